@@ -0,0 +1,99 @@
+package gameblocks
+
+import (
+	"fmt"
+
+	"golang.org/x/mobile/gl"
+)
+
+// AttribType is an element type which can back a vertex attribute.
+// INT and UNSIGNED_INT attributes need OpenGL ES 3.
+type AttribType interface {
+	int8 | uint8 | int16 | uint16 | int32 | uint32 | float32
+}
+
+// AttribSlice is a DimSlicer of vertex attribute data with dim components
+// per vertex, such as Vec3 positions or RGBA byte colors.
+type AttribSlice[T AttribType] struct {
+	data       []T
+	dim        int
+	normalized bool
+}
+
+// NewAttribSlice returns an AttribSlice of data with dim components per
+// vertex.
+func NewAttribSlice[T AttribType](dim int, data []T) *AttribSlice[T] {
+	return &AttribSlice[T]{data: data, dim: dim}
+}
+
+// NewNormalizedSlice returns an AttribSlice whose integer values are mapped to
+// [0, 1] (unsigned) or [-1, 1] (signed) when read by the shader, for packed
+// normals and byte colors.
+func NewNormalizedSlice[T AttribType](dim int, data []T) *AttribSlice[T] {
+	return &AttribSlice[T]{data: data, dim: dim, normalized: true}
+}
+
+// Data returns the underlying slice.
+func (o *AttribSlice[T]) Data() []T { return o.data }
+
+// Len returns the number of vertices.
+func (o *AttribSlice[T]) Len() int { return len(o.data) / o.dim }
+
+func (o *AttribSlice[T]) Slice(i, j int) interface{} { return o.data[i:j] }
+func (o *AttribSlice[T]) Dim() int                   { return o.dim }
+func (o *AttribSlice[T]) Normalized() bool           { return o.normalized }
+
+// Type returns the GL type of the elements, for VertexAttribPointer.
+func (o *AttribSlice[T]) Type() gl.Enum {
+	var zero T
+	switch any(zero).(type) {
+	case int8:
+		return gl.BYTE
+	case uint8:
+		return gl.UNSIGNED_BYTE
+	case int16:
+		return gl.SHORT
+	case uint16:
+		return gl.UNSIGNED_SHORT
+	case int32:
+		return gl.INT
+	case uint32:
+		return gl.UNSIGNED_INT
+	}
+	return gl.FLOAT
+}
+
+func (o *AttribSlice[T]) Size() int {
+	var zero T
+	switch any(zero).(type) {
+	case int8, uint8:
+		return 1
+	case int16, uint16:
+		return 2
+	}
+	return 4
+}
+
+func (o *AttribSlice[T]) Encode(enc *Encoder, i, j int) {
+	switch data := any(o.data[i:j]).(type) {
+	case []int8:
+		enc.Int8s(data)
+	case []uint8:
+		enc.Uint8s(data)
+	case []int16:
+		enc.Int16s(data)
+	case []uint16:
+		enc.Uint16s(data)
+	case []int32:
+		enc.Int32s(data)
+	case []uint32:
+		enc.Uint32s(data)
+	case []float32:
+		enc.Float32s(data)
+	}
+}
+
+func (o *AttribSlice[T]) String() string {
+	var zero T
+	return fmt.Sprintf("<%T slice: len=%d dim=%d>", zero, len(o.data), o.dim)
+}
@@ -0,0 +1,40 @@
+package gameblocks
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/mobile/gl"
+)
+
+func TestAttribSlice(t *testing.T) {
+	tests := []struct {
+		slice DimSlicer
+		typ   gl.Enum
+		size  int
+		want  []byte
+	}{
+		{NewAttribSlice(2, []int8{-1, 2}), gl.BYTE, 1, []byte{0xff, 2}},
+		{NewNormalizedSlice(2, []uint8{255, 0}), gl.UNSIGNED_BYTE, 1, []byte{255, 0}},
+		{NewNormalizedSlice(2, []int16{-2, 1}), gl.SHORT, 2, []byte{0xfe, 0xff, 1, 0}},
+		{NewAttribSlice(2, []uint16{1, 256}), gl.UNSIGNED_SHORT, 2, []byte{1, 0, 0, 1}},
+		{NewAttribSlice(1, []int32{-1}), gl.INT, 4, []byte{0xff, 0xff, 0xff, 0xff}},
+		{NewAttribSlice(1, []uint32{1 << 24}), gl.UNSIGNED_INT, 4, []byte{0, 0, 0, 1}},
+		{NewAttribSlice(1, []float32{1}), gl.FLOAT, 4, []byte{0, 0, 0x80, 0x3f}},
+	}
+	for _, test := range tests {
+		if got := test.slice.Type(); got != test.typ {
+			t.Errorf("%v: got type %v; want %v", test.slice, got, test.typ)
+		}
+		if got := test.slice.Size(); got != test.size {
+			t.Errorf("%v: got size %d; want %d", test.slice, got, test.size)
+		}
+		if got := EncodeObjects(0, 1, test.slice); !bytes.Equal(got, test.want) {
+			t.Errorf("%v: got %v; want %v", test.slice, got, test.want)
+		}
+	}
+
+	if NewAttribSlice(3, []int8{}).Normalized() || !NewNormalizedSlice(3, []int8{}).Normalized() {
+		t.Error("wrong normalized flag")
+	}
+}
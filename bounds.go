@@ -0,0 +1,134 @@
+package gameblocks
+
+import (
+	"math"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+// AABB is an axis-aligned bounding box.
+type AABB struct {
+	Min, Max mgl.Vec3
+}
+
+// NewAABB returns the bounds of a list of Vec3 positions.
+func NewAABB(positions []float32) AABB {
+	if len(positions) < vertexDim {
+		return AABB{}
+	}
+	b := AABB{
+		Min: mgl.Vec3{positions[0], positions[1], positions[2]},
+		Max: mgl.Vec3{positions[0], positions[1], positions[2]},
+	}
+	for i := vertexDim; i+vertexDim <= len(positions); i += vertexDim {
+		for j := 0; j < vertexDim; j++ {
+			v := positions[i+j]
+			if v < b.Min[j] {
+				b.Min[j] = v
+			}
+			if v > b.Max[j] {
+				b.Max[j] = v
+			}
+		}
+	}
+	return b
+}
+
+// Center returns the middle of the box.
+func (b AABB) Center() mgl.Vec3 {
+	return b.Min.Add(b.Max).Mul(0.5)
+}
+
+// Extents returns the half-size of the box along each axis.
+func (b AABB) Extents() mgl.Vec3 {
+	return b.Max.Sub(b.Min).Mul(0.5)
+}
+
+// Union returns the smallest box containing both boxes.
+func (b AABB) Union(other AABB) AABB {
+	for i := 0; i < 3; i++ {
+		b.Min[i] = float32(math.Min(float64(b.Min[i]), float64(other.Min[i])))
+		b.Max[i] = float32(math.Max(float64(b.Max[i]), float64(other.Max[i])))
+	}
+	return b
+}
+
+// Transform returns the box which bounds this one after transformation by m.
+func (b AABB) Transform(m mgl.Mat4) AABB {
+	center := m.Mul4x1(b.Center().Vec4(1)).Vec3()
+	e := b.Extents()
+	var extents mgl.Vec3
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			extents[row] += float32(math.Abs(float64(m.At(row, col)))) * e[col]
+		}
+	}
+	return AABB{center.Sub(extents), center.Add(extents)}
+}
+
+// Sphere returns the bounding sphere of the box.
+func (b AABB) Sphere() Sphere {
+	return Sphere{Center: b.Center(), Radius: b.Extents().Len()}
+}
+
+// Sphere is a bounding sphere.
+type Sphere struct {
+	Center mgl.Vec3
+	Radius float32
+}
+
+// Frustum is the six clipping planes of a view volume, as (normal, distance)
+// with normals facing inwards: left, right, bottom, top, near and far.
+type Frustum [6]mgl.Vec4
+
+// NewFrustum extracts the frustum from a projection * view matrix, such as
+// Camera.Projection().Mul4(Camera.View()).
+func NewFrustum(viewProjection mgl.Mat4) Frustum {
+	m := viewProjection
+	row := func(i int) mgl.Vec4 {
+		return mgl.Vec4{m.At(i, 0), m.At(i, 1), m.At(i, 2), m.At(i, 3)}
+	}
+	r0, r1, r2, r3 := row(0), row(1), row(2), row(3)
+	f := Frustum{
+		r3.Add(r0), r3.Sub(r0),
+		r3.Add(r1), r3.Sub(r1),
+		r3.Add(r2), r3.Sub(r2),
+	}
+	for i, p := range f {
+		// A degenerate plane is left as zero, which culls nothing.
+		if l := p.Vec3().Len(); l > 0 {
+			f[i] = p.Mul(1 / l)
+		}
+	}
+	return f
+}
+
+// IntersectsSphere reports whether any part of the sphere is inside.
+func (f Frustum) IntersectsSphere(s Sphere) bool {
+	for _, p := range f {
+		if p.Vec3().Dot(s.Center)+p[3] < -s.Radius {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsAABB reports whether any part of the box may be inside. Boxes near
+// the corners of the frustum can be reported inside when they are not.
+func (f Frustum) IntersectsAABB(b AABB) bool {
+	for _, p := range f {
+		// The corner furthest along the plane normal.
+		var v mgl.Vec3
+		for i := 0; i < 3; i++ {
+			if p[i] >= 0 {
+				v[i] = b.Max[i]
+			} else {
+				v[i] = b.Min[i]
+			}
+		}
+		if p.Vec3().Dot(v)+p[3] < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package gameblocks
+
+import (
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/shazow/go-gameblocks/camera"
+	"github.com/shazow/go-gameblocks/gltest"
+)
+
+func TestAABBTransform(t *testing.T) {
+	b := NewAABB([]float32{-1, 0, 0, 1, 2, 0, 0, -2, 3})
+	if want := (AABB{mgl.Vec3{-1, -2, 0}, mgl.Vec3{1, 2, 3}}); b != want {
+		t.Fatalf("got %v; want %v", b, want)
+	}
+
+	// Rotating a quarter turn about Z swaps the X and Y extents.
+	m := mgl.Translate3D(10, 0, 0).Mul4(mgl.HomogRotate3DZ(mgl.DegToRad(90)))
+	got := b.Transform(m)
+	want := AABB{mgl.Vec3{8, -1, 0}, mgl.Vec3{12, 1, 3}}
+	if !got.Min.ApproxEqualThreshold(want.Min, 1e-5) || !got.Max.ApproxEqualThreshold(want.Max, 1e-5) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestFrustumCulling(t *testing.T) {
+	cam := camera.NewQuatCamera()
+	cam.SetPerspective(mgl.DegToRad(60), 1, 0.1, 100)
+	f := NewFrustum(cam.Projection().Mul4(cam.View()))
+
+	for _, tc := range []struct {
+		center mgl.Vec3
+		inside bool
+	}{
+		{mgl.Vec3{0, 0, -10}, true},
+		{mgl.Vec3{0, 0, 10}, false},
+		{mgl.Vec3{100, 0, -10}, false},
+		{mgl.Vec3{0, 0, -200}, false},
+		{mgl.Vec3{6, 0, -10}, true}, // Straddles the right plane
+	} {
+		b := AABB{tc.center.Sub(mgl.Vec3{1, 1, 1}), tc.center.Add(mgl.Vec3{1, 1, 1})}
+		if got := f.IntersectsAABB(b); got != tc.inside {
+			t.Errorf("IntersectsAABB(%v) = %v; want %v", tc.center, got, tc.inside)
+		}
+		if got := f.IntersectsSphere(b.Sphere()); got != tc.inside {
+			t.Errorf("IntersectsSphere(%v) = %v; want %v", tc.center, got, tc.inside)
+		}
+	}
+
+	glctx := gltest.NewContext()
+	shader := newTestShader(t, glctx)
+	shape := NewStaticShape(glctx)
+	shape.vertices = []float32{-1, -1, 0, 1, -1, 0, 0, 1, 0}
+	shape.Buffer()
+
+	scene := NewScene()
+	for _, z := range []float32{-10, 10, -20} {
+		node := NewNode(shape, shader)
+		transform := mgl.Translate3D(0, 0, z)
+		node.SetTransform(&transform)
+		scene.Add(node)
+	}
+	scene.Draw(FrameContext{GL: glctx, Camera: cam})
+
+	if got, want := scene.Stats(), (RenderStats{Drawn: 2, Culled: 1}); got != want {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+}
@@ -1,6 +1,6 @@
 package control
 
-import key "golang.org/x/mobile/event/key"
+import "github.com/shazow/go-gameblocks/event"
 
 type KeyBinding uint8
 
@@ -21,19 +21,19 @@ const (
 )
 
 func DefaultBindings() Bindings {
-	b := map[key.Code]KeyBinding{
-		key.CodeW:          KeyCamForward,
-		key.CodeS:          KeyCamReverse,
-		key.CodeA:          KeyCamLeft,
-		key.CodeD:          KeyCamRight,
-		key.CodeQ:          KeyCamUp,
-		key.CodeE:          KeyCamDown,
-		key.CodeRightArrow: KeyLineRight,
-		key.CodeLeftArrow:  KeyLineLeft,
-		key.CodeF:          KeyCameraFollow,
-		key.CodeSpacebar:   KeyPause,
-		key.CodeR:          KeyReload,
-		key.CodeBackslash:  KeyDebug,
+	b := map[event.Code]KeyBinding{
+		event.CodeW:          KeyCamForward,
+		event.CodeS:          KeyCamReverse,
+		event.CodeA:          KeyCamLeft,
+		event.CodeD:          KeyCamRight,
+		event.CodeQ:          KeyCamUp,
+		event.CodeE:          KeyCamDown,
+		event.CodeRightArrow: KeyLineRight,
+		event.CodeLeftArrow:  KeyLineLeft,
+		event.CodeF:          KeyCameraFollow,
+		event.CodeSpacebar:   KeyPause,
+		event.CodeR:          KeyReload,
+		event.CodeBackslash:  KeyDebug,
 	}
 	return &bindings{
 		bindings: b,
@@ -43,20 +43,20 @@ func DefaultBindings() Bindings {
 }
 
 type Bindings interface {
-	Lookup(code key.Code) KeyBinding
+	Lookup(code event.Code) KeyBinding
 	On(k KeyBinding, fn func(KeyBinding))
-	Press(code key.Code)
+	Press(code event.Code)
 	Pressed(k KeyBinding) bool
-	Release(code key.Code)
+	Release(code event.Code)
 }
 
 type bindings struct {
-	bindings map[key.Code]KeyBinding
+	bindings map[event.Code]KeyBinding
 	on       map[KeyBinding]func(KeyBinding)
 	pressed  map[KeyBinding]bool
 }
 
-func (b *bindings) Lookup(code key.Code) KeyBinding {
+func (b *bindings) Lookup(code event.Code) KeyBinding {
 	k, ok := b.bindings[code]
 	if !ok {
 		return KeyUnknown
@@ -68,7 +68,7 @@ func (b *bindings) On(k KeyBinding, fn func(KeyBinding)) {
 	b.on[k] = fn
 }
 
-func (b *bindings) Press(code key.Code) {
+func (b *bindings) Press(code event.Code) {
 	key := b.Lookup(code)
 	b.pressed[key] = true
 
@@ -78,7 +78,7 @@ func (b *bindings) Press(code key.Code) {
 	}
 }
 
-func (b *bindings) Release(code key.Code) {
+func (b *bindings) Release(code event.Code) {
 	key := b.Lookup(code)
 	b.pressed[key] = false
 }
@@ -0,0 +1,395 @@
+//go:build !android && !ios
+// +build !android,!ios
+
+package gameblocks
+
+import (
+	"runtime"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/shazow/go-gameblocks/event"
+	"golang.org/x/mobile/gl"
+)
+
+// DesktopOptions configures the window created by DesktopPlatform.
+type DesktopOptions struct {
+	Title  string
+	Width  int // Window width in screen coordinates, defaults to 1024.
+	Height int // Window height in screen coordinates, defaults to 768.
+}
+
+// StartDesktop launches the Engine in a GLFW window, should be run from the
+// main thread. It returns once the window is closed.
+//
+// The window has an OpenGL ES 3 context where available, else OpenGL ES 2.
+// On macOS, which has no OpenGL ES, it is an OpenGL 3.2 core profile context
+// instead, so shaders have to be written for GLSL 1.50 there.
+func StartDesktop(engine Engine, opts DesktopOptions) error {
+	return Start(DesktopPlatform(opts), engine)
+}
+
+// DesktopPlatform returns the Platform for the GLFW backend.
+func DesktopPlatform(opts DesktopOptions) Platform {
+	if opts.Width == 0 {
+		opts.Width = 1024
+	}
+	if opts.Height == 0 {
+		opts.Height = 768
+	}
+	return &desktop{opts: opts}
+}
+
+// desktop holds the state of the GLFW backend. Callbacks are invoked on the
+// main thread during PollEvents and queue translated events for the next
+// frame.
+type desktop struct {
+	opts   DesktopOptions
+	window *glfw.Window
+	worker gl.Worker
+	calls  chan func() // Run on the main thread during call
+
+	events  []interface{}
+	pressed bool
+	pointer int64
+}
+
+func (d *desktop) Run(handle func(e interface{}) error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := glfw.Init(); err != nil {
+		return err
+	}
+	defer glfw.Terminate()
+
+	window, err := d.createWindow()
+	if err != nil {
+		return err
+	}
+	defer window.Destroy()
+	d.window = window
+
+	window.MakeContextCurrent()
+	glfw.SwapInterval(1)
+
+	glctx, worker := gl.NewContext()
+	d.worker = worker
+	d.calls = make(chan func())
+	glctx = d.withES3(glctx)
+	window.SetFramebufferSizeCallback(d.onResize)
+	window.SetKeyCallback(d.onKey)
+	window.SetMouseButtonCallback(d.onMouseButton)
+	window.SetCursorPosCallback(d.onCursorPos)
+
+	d.call(func() {
+		err = handle(event.Lifecycle{Stage: event.StageVisible, GL: glctx})
+	})
+	if err != nil {
+		return err
+	}
+
+	d.onResize(window, 0, 0)
+	for !window.ShouldClose() && err == nil {
+		glfw.PollEvents()
+		events := append(d.events, event.Paint{})
+		d.events = nil
+
+		d.call(func() {
+			for _, e := range events {
+				if sz, ok := e.(event.Resize); ok {
+					glctx.Viewport(0, 0, sz.WidthPx, sz.HeightPx)
+				}
+				if err = handle(e); err != nil {
+					return
+				}
+			}
+		})
+		window.SwapBuffers()
+	}
+
+	d.call(func() {
+		if stopErr := handle(event.Lifecycle{Stage: event.StageHidden}); err == nil {
+			err = stopErr
+		}
+	})
+	return err
+}
+
+// createWindow opens the window with the most capable context available.
+func (d *desktop) createWindow() (*glfw.Window, error) {
+	glfw.WindowHint(glfw.DepthBits, 24)
+	glfw.WindowHint(glfw.StencilBits, 8)
+
+	if runtime.GOOS == "darwin" {
+		// macOS has no OpenGL ES, and x/mobile/gl calls desktop OpenGL there,
+		// so use a core profile. Shaders have to be GLSL 1.50 to compile.
+		glfw.WindowHint(glfw.ClientAPI, glfw.OpenGLAPI)
+		glfw.WindowHint(glfw.ContextVersionMajor, 3)
+		glfw.WindowHint(glfw.ContextVersionMinor, 2)
+		glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+		glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+		return glfw.CreateWindow(d.opts.Width, d.opts.Height, d.opts.Title, nil, nil)
+	}
+
+	// Prefer OpenGL ES 3, for instancing and uniform blocks, falling back to
+	// the OpenGL ES 2 of the x/mobile backend.
+	glfw.WindowHint(glfw.ClientAPI, glfw.OpenGLESAPI)
+	glfw.WindowHint(glfw.ContextVersionMajor, 3)
+	glfw.WindowHint(glfw.ContextVersionMinor, 0)
+	window, err := glfw.CreateWindow(d.opts.Width, d.opts.Height, d.opts.Title, nil, nil)
+	if err != nil {
+		glfw.WindowHint(glfw.ContextVersionMajor, 2)
+		window, err = glfw.CreateWindow(d.opts.Width, d.opts.Height, d.opts.Title, nil, nil)
+	}
+	return window, err
+}
+
+// call runs fn on another goroutine while the GL calls it makes are executed
+// on this one, which owns the GL context.
+func (d *desktop) call(fn func()) {
+	done := make(chan struct{})
+	go func() {
+		fn()
+		close(done)
+	}()
+
+	work := d.worker.WorkAvailable()
+	for {
+		select {
+		case <-work:
+			d.worker.DoWork()
+		case fn := <-d.calls:
+			fn()
+		case <-done:
+			return
+		}
+	}
+}
+
+// scale returns the ratio of framebuffer pixels to screen coordinates.
+func (d *desktop) scale() float32 {
+	w, _ := d.window.GetSize()
+	fw, _ := d.window.GetFramebufferSize()
+	if w == 0 {
+		return 1
+	}
+	return float32(fw) / float32(w)
+}
+
+func (d *desktop) onResize(w *glfw.Window, _, _ int) {
+	// Query rather than trust the arguments so the initial event can be
+	// synthesized with zeroes.
+	width, height := w.GetFramebufferSize()
+	d.events = append(d.events, event.Resize{
+		WidthPx:     width,
+		HeightPx:    height,
+		PixelsPerPt: d.scale(),
+	})
+}
+
+// The mouse is treated as a single finger: a held button produces a pointer
+// sequence, like the x/mobile desktop backends.
+func (d *desktop) onMouseButton(w *glfw.Window, button glfw.MouseButton, action glfw.Action, _ glfw.ModifierKey) {
+	if button != glfw.MouseButtonLeft {
+		return
+	}
+	t := event.PointerDown
+	switch action {
+	case glfw.Press:
+		d.pressed = true
+	case glfw.Release:
+		d.pressed = false
+		t = event.PointerUp
+	default:
+		return
+	}
+	x, y := w.GetCursorPos()
+	d.pointerEvent(x, y, t)
+	if t == event.PointerUp {
+		d.pointer++
+	}
+}
+
+func (d *desktop) onCursorPos(_ *glfw.Window, x, y float64) {
+	if d.pressed {
+		d.pointerEvent(x, y, event.PointerMove)
+	}
+}
+
+func (d *desktop) pointerEvent(x, y float64, t event.PointerType) {
+	scale := d.scale()
+	d.events = append(d.events, event.Pointer{
+		X:    float32(x) * scale,
+		Y:    float32(y) * scale,
+		ID:   d.pointer,
+		Type: t,
+	})
+}
+
+func (d *desktop) onKey(_ *glfw.Window, k glfw.Key, _ int, action glfw.Action, mods glfw.ModifierKey) {
+	var dir event.Direction
+	switch action {
+	case glfw.Press:
+		dir = event.DirPress
+	case glfw.Release:
+		dir = event.DirRelease
+	default:
+		// Repeats are reported with DirNone, as x/mobile does.
+		dir = event.DirNone
+	}
+
+	code, ok := glfwKeyCodes[k]
+	if !ok {
+		code = event.CodeUnknown
+	}
+
+	var modifiers event.Modifiers
+	if mods&glfw.ModShift != 0 {
+		modifiers |= event.ModShift
+	}
+	if mods&glfw.ModControl != 0 {
+		modifiers |= event.ModControl
+	}
+	if mods&glfw.ModAlt != 0 {
+		modifiers |= event.ModAlt
+	}
+	if mods&glfw.ModSuper != 0 {
+		modifiers |= event.ModMeta
+	}
+
+	d.events = append(d.events, event.Key{
+		Rune:      glfwKeyRune(k, modifiers),
+		Code:      code,
+		Modifiers: modifiers,
+		Direction: dir,
+	})
+}
+
+// glfwKeyRune returns the rune for keys which map to a single printable
+// character, or -1.
+func glfwKeyRune(k glfw.Key, mods event.Modifiers) rune {
+	switch {
+	case k >= glfw.KeyA && k <= glfw.KeyZ:
+		if mods&event.ModShift != 0 {
+			return rune('A' + k - glfw.KeyA)
+		}
+		return rune('a' + k - glfw.KeyA)
+	case k >= glfw.Key0 && k <= glfw.Key9 && mods&event.ModShift == 0:
+		return rune('0' + k - glfw.Key0)
+	case k == glfw.KeySpace:
+		return ' '
+	}
+	return -1
+}
+
+var glfwKeyCodes = map[glfw.Key]event.Code{
+	glfw.KeyA: event.CodeA,
+	glfw.KeyB: event.CodeB,
+	glfw.KeyC: event.CodeC,
+	glfw.KeyD: event.CodeD,
+	glfw.KeyE: event.CodeE,
+	glfw.KeyF: event.CodeF,
+	glfw.KeyG: event.CodeG,
+	glfw.KeyH: event.CodeH,
+	glfw.KeyI: event.CodeI,
+	glfw.KeyJ: event.CodeJ,
+	glfw.KeyK: event.CodeK,
+	glfw.KeyL: event.CodeL,
+	glfw.KeyM: event.CodeM,
+	glfw.KeyN: event.CodeN,
+	glfw.KeyO: event.CodeO,
+	glfw.KeyP: event.CodeP,
+	glfw.KeyQ: event.CodeQ,
+	glfw.KeyR: event.CodeR,
+	glfw.KeyS: event.CodeS,
+	glfw.KeyT: event.CodeT,
+	glfw.KeyU: event.CodeU,
+	glfw.KeyV: event.CodeV,
+	glfw.KeyW: event.CodeW,
+	glfw.KeyX: event.CodeX,
+	glfw.KeyY: event.CodeY,
+	glfw.KeyZ: event.CodeZ,
+
+	glfw.Key1: event.Code1,
+	glfw.Key2: event.Code2,
+	glfw.Key3: event.Code3,
+	glfw.Key4: event.Code4,
+	glfw.Key5: event.Code5,
+	glfw.Key6: event.Code6,
+	glfw.Key7: event.Code7,
+	glfw.Key8: event.Code8,
+	glfw.Key9: event.Code9,
+	glfw.Key0: event.Code0,
+
+	glfw.KeyEnter:        event.CodeReturnEnter,
+	glfw.KeyEscape:       event.CodeEscape,
+	glfw.KeyBackspace:    event.CodeDeleteBackspace,
+	glfw.KeyTab:          event.CodeTab,
+	glfw.KeySpace:        event.CodeSpacebar,
+	glfw.KeyMinus:        event.CodeHyphenMinus,
+	glfw.KeyEqual:        event.CodeEqualSign,
+	glfw.KeyLeftBracket:  event.CodeLeftSquareBracket,
+	glfw.KeyRightBracket: event.CodeRightSquareBracket,
+	glfw.KeyBackslash:    event.CodeBackslash,
+	glfw.KeySemicolon:    event.CodeSemicolon,
+	glfw.KeyApostrophe:   event.CodeApostrophe,
+	glfw.KeyGraveAccent:  event.CodeGraveAccent,
+	glfw.KeyComma:        event.CodeComma,
+	glfw.KeyPeriod:       event.CodeFullStop,
+	glfw.KeySlash:        event.CodeSlash,
+	glfw.KeyCapsLock:     event.CodeCapsLock,
+
+	glfw.KeyF1:  event.CodeF1,
+	glfw.KeyF2:  event.CodeF2,
+	glfw.KeyF3:  event.CodeF3,
+	glfw.KeyF4:  event.CodeF4,
+	glfw.KeyF5:  event.CodeF5,
+	glfw.KeyF6:  event.CodeF6,
+	glfw.KeyF7:  event.CodeF7,
+	glfw.KeyF8:  event.CodeF8,
+	glfw.KeyF9:  event.CodeF9,
+	glfw.KeyF10: event.CodeF10,
+	glfw.KeyF11: event.CodeF11,
+	glfw.KeyF12: event.CodeF12,
+
+	glfw.KeyPause:    event.CodePause,
+	glfw.KeyInsert:   event.CodeInsert,
+	glfw.KeyHome:     event.CodeHome,
+	glfw.KeyPageUp:   event.CodePageUp,
+	glfw.KeyDelete:   event.CodeDeleteForward,
+	glfw.KeyEnd:      event.CodeEnd,
+	glfw.KeyPageDown: event.CodePageDown,
+
+	glfw.KeyRight: event.CodeRightArrow,
+	glfw.KeyLeft:  event.CodeLeftArrow,
+	glfw.KeyDown:  event.CodeDownArrow,
+	glfw.KeyUp:    event.CodeUpArrow,
+
+	glfw.KeyNumLock:     event.CodeKeypadNumLock,
+	glfw.KeyKPDivide:    event.CodeKeypadSlash,
+	glfw.KeyKPMultiply:  event.CodeKeypadAsterisk,
+	glfw.KeyKPSubtract:  event.CodeKeypadHyphenMinus,
+	glfw.KeyKPAdd:       event.CodeKeypadPlusSign,
+	glfw.KeyKPEnter:     event.CodeKeypadEnter,
+	glfw.KeyKP1:         event.CodeKeypad1,
+	glfw.KeyKP2:         event.CodeKeypad2,
+	glfw.KeyKP3:         event.CodeKeypad3,
+	glfw.KeyKP4:         event.CodeKeypad4,
+	glfw.KeyKP5:         event.CodeKeypad5,
+	glfw.KeyKP6:         event.CodeKeypad6,
+	glfw.KeyKP7:         event.CodeKeypad7,
+	glfw.KeyKP8:         event.CodeKeypad8,
+	glfw.KeyKP9:         event.CodeKeypad9,
+	glfw.KeyKP0:         event.CodeKeypad0,
+	glfw.KeyKPDecimal:   event.CodeKeypadFullStop,
+	glfw.KeyKPEqual:     event.CodeKeypadEqualSign,
+	glfw.KeyLeftControl: event.CodeLeftControl,
+	glfw.KeyLeftShift:   event.CodeLeftShift,
+	glfw.KeyLeftAlt:     event.CodeLeftAlt,
+	glfw.KeyLeftSuper:   event.CodeLeftGUI,
+
+	glfw.KeyRightControl: event.CodeRightControl,
+	glfw.KeyRightShift:   event.CodeRightShift,
+	glfw.KeyRightAlt:     event.CodeRightAlt,
+	glfw.KeyRightSuper:   event.CodeRightGUI,
+}
@@ -0,0 +1,89 @@
+//go:build !android && !ios
+// +build !android,!ios
+
+package gameblocks
+
+import (
+	"github.com/go-gl/gl/v3.1/gles2"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"golang.org/x/mobile/gl"
+)
+
+// desktopContext3 adds the OpenGL ES 3 calls which golang.org/x/mobile/gl
+// lacks, made through go-gl, so the desktop backend is an InstancedContext and
+// a UniformBlockContext.
+type desktopContext3 struct {
+	gl.Context3
+	d *desktop
+}
+
+var (
+	_ InstancedContext    = desktopContext3{}
+	_ UniformBlockContext = desktopContext3{}
+)
+
+// withES3 wraps glctx with the calls of desktopContext3 if the window got an
+// OpenGL ES 3 context and they can be loaded from it. Otherwise it hides the
+// gl.Context3 methods, which x/mobile/gl has whenever it is built for ES 3,
+// so that they aren't called on an ES 2 context.
+func (d *desktop) withES3(glctx gl.Context) gl.Context {
+	plain := struct{ gl.Context }{glctx}
+	glctx3, ok := glctx.(gl.Context3)
+	if !ok || d.window.GetAttrib(glfw.ContextVersionMajor) < 3 {
+		return plain
+	}
+	if err := gles2.InitWithProcAddrFunc(glfw.GetProcAddress); err != nil {
+		return plain
+	}
+	return desktopContext3{glctx3, d}
+}
+
+// run calls fn on the main thread, which owns the GL context, after the calls
+// x/mobile/gl has queued so they keep their order.
+func (d *desktop) run(fn func()) {
+	done := make(chan struct{})
+	d.calls <- func() {
+		d.worker.DoWork()
+		fn()
+		close(done)
+	}
+	<-done
+}
+
+func (c desktopContext3) VertexAttribDivisor(index gl.Attrib, divisor int) {
+	c.d.run(func() {
+		gles2.VertexAttribDivisor(uint32(index.Value), uint32(divisor))
+	})
+}
+
+func (c desktopContext3) DrawArraysInstanced(mode gl.Enum, first, count, instances int) {
+	c.d.run(func() {
+		gles2.DrawArraysInstanced(uint32(mode), int32(first), int32(count), int32(instances))
+	})
+}
+
+func (c desktopContext3) DrawElementsInstanced(mode gl.Enum, count int, ty gl.Enum, offset, instances int) {
+	c.d.run(func() {
+		gles2.DrawElementsInstanced(uint32(mode), int32(count), uint32(ty), gles2.PtrOffset(offset), int32(instances))
+	})
+}
+
+func (c desktopContext3) GetUniformBlockIndex(p gl.Program, name string) uint32 {
+	var index uint32
+	c.d.run(func() {
+		index = gles2.GetUniformBlockIndex(p.Value, gles2.Str(name+"\x00"))
+	})
+	return index
+}
+
+func (c desktopContext3) UniformBlockBinding(p gl.Program, index, binding uint32) {
+	c.d.run(func() {
+		gles2.UniformBlockBinding(p.Value, index, binding)
+	})
+}
+
+func (c desktopContext3) BindBufferBase(target gl.Enum, index uint32, b gl.Buffer) {
+	c.d.run(func() {
+		gles2.BindBufferBase(uint32(target), index, b.Value)
+	})
+}
@@ -0,0 +1,51 @@
+//go:build !android && !ios
+// +build !android,!ios
+
+package gameblocks
+
+import (
+	"testing"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/shazow/go-gameblocks/event"
+)
+
+func TestGLFWKeys(t *testing.T) {
+	tests := []struct {
+		key  glfw.Key
+		mods event.Modifiers
+		code event.Code
+		rune rune
+	}{
+		// Keys of control.DefaultBindings.
+		{glfw.KeyW, 0, event.CodeW, 'w'},
+		{glfw.KeyA, 0, event.CodeA, 'a'},
+		{glfw.KeyS, 0, event.CodeS, 's'},
+		{glfw.KeyD, 0, event.CodeD, 'd'},
+		{glfw.KeyQ, 0, event.CodeQ, 'q'},
+		{glfw.KeyE, 0, event.CodeE, 'e'},
+		{glfw.KeyF, 0, event.CodeF, 'f'},
+		{glfw.KeyR, 0, event.CodeR, 'r'},
+		{glfw.KeySpace, 0, event.CodeSpacebar, ' '},
+		{glfw.KeyBackslash, 0, event.CodeBackslash, -1},
+		{glfw.KeyLeft, 0, event.CodeLeftArrow, -1},
+		{glfw.KeyRight, 0, event.CodeRightArrow, -1},
+
+		{glfw.KeyZ, event.ModShift, event.CodeZ, 'Z'},
+		{glfw.Key0, 0, event.Code0, '0'},
+		{glfw.Key9, 0, event.Code9, '9'},
+		{glfw.Key1, event.ModShift, event.Code1, -1},
+		{glfw.KeyEscape, 0, event.CodeEscape, -1},
+		{glfw.KeyEnter, 0, event.CodeReturnEnter, -1},
+		{glfw.KeyKPEnter, 0, event.CodeKeypadEnter, -1},
+		{glfw.KeyLeftShift, event.ModShift, event.CodeLeftShift, -1},
+	}
+	for _, test := range tests {
+		if got := glfwKeyCodes[test.key]; got != test.code {
+			t.Errorf("key %d: got code %v; want %v", test.key, got, test.code)
+		}
+		if got := glfwKeyRune(test.key, test.mods); got != test.rune {
+			t.Errorf("key %d: got rune %q; want %q", test.key, got, test.rune)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package gameblocks
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Encoder writes vertex data as little-endian bytes into a buffer which is
+// reused between encodes, so steady-state encoding does not allocate. The zero
+// value is ready to use.
+type Encoder struct {
+	buf []byte
+}
+
+// Reset empties the buffer, growing its capacity to at least size bytes.
+func (enc *Encoder) Reset(size int) {
+	if cap(enc.buf) < size {
+		enc.buf = make([]byte, 0, size)
+	}
+	enc.buf = enc.buf[:0]
+}
+
+// Bytes returns the encoded data. It is only valid until the next Reset or
+// Encode.
+func (enc *Encoder) Bytes() []byte {
+	return enc.buf
+}
+
+// Len returns the number of bytes encoded since the last Reset.
+func (enc *Encoder) Len() int {
+	return len(enc.buf)
+}
+
+// grow extends the buffer by n bytes and returns them.
+func (enc *Encoder) grow(n int) []byte {
+	l := len(enc.buf)
+	if l+n > cap(enc.buf) {
+		buf := make([]byte, l, 2*cap(enc.buf)+n)
+		copy(buf, enc.buf)
+		enc.buf = buf
+	}
+	enc.buf = enc.buf[:l+n]
+	return enc.buf[l:]
+}
+
+func (enc *Encoder) Float32s(v []float32) {
+	b := enc.grow(len(v) * 4)
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(b[i*4:], math.Float32bits(f))
+	}
+}
+
+func (enc *Encoder) Int8s(v []int8) {
+	b := enc.grow(len(v))
+	for i, n := range v {
+		b[i] = uint8(n)
+	}
+}
+
+func (enc *Encoder) Uint8s(v []uint8) {
+	copy(enc.grow(len(v)), v)
+}
+
+func (enc *Encoder) Int16s(v []int16) {
+	b := enc.grow(len(v) * 2)
+	for i, n := range v {
+		binary.LittleEndian.PutUint16(b[i*2:], uint16(n))
+	}
+}
+
+func (enc *Encoder) Uint16s(v []uint16) {
+	b := enc.grow(len(v) * 2)
+	for i, n := range v {
+		binary.LittleEndian.PutUint16(b[i*2:], n)
+	}
+}
+
+func (enc *Encoder) Int32s(v []int32) {
+	b := enc.grow(len(v) * 4)
+	for i, n := range v {
+		binary.LittleEndian.PutUint32(b[i*4:], uint32(n))
+	}
+}
+
+func (enc *Encoder) Uint32s(v []uint32) {
+	b := enc.grow(len(v) * 4)
+	for i, n := range v {
+		binary.LittleEndian.PutUint32(b[i*4:], n)
+	}
+}
+
+// Encode resets the buffer and interleaves rows offset to length of objects,
+// like EncodeObjects.
+func (enc *Encoder) Encode(offset int, length int, objects ...DimSlicer) []byte {
+	rowSize := 0
+	for _, obj := range objects {
+		rowSize += obj.Dim() * obj.Size()
+	}
+	if length < offset {
+		length = offset
+	}
+	enc.Reset(rowSize * (length - offset))
+
+	for i := offset; i < length; i++ {
+		for _, obj := range objects {
+			obj.Encode(enc, i*obj.Dim(), (i+1)*obj.Dim())
+		}
+	}
+	return enc.buf
+}
+
+// EncodeLayout resets the buffer and interleaves rows offset to length of
+// objects at the offsets and stride of layout, which has an attribute for
+// each object in order. Padding between attributes is zeroed.
+func (enc *Encoder) EncodeLayout(layout VertexLayout, offset int, length int, objects ...DimSlicer) []byte {
+	if len(objects) != len(layout.Attribs) {
+		panic(fmt.Sprintf("gameblocks: %d objects for a layout of %d attributes", len(objects), len(layout.Attribs)))
+	}
+	if length < offset {
+		length = offset
+	}
+	enc.Reset(layout.Stride * (length - offset))
+
+	for i := offset; i < length; i++ {
+		row := len(enc.buf)
+		for j, obj := range objects {
+			enc.pad(row + layout.Attribs[j].Offset)
+			obj.Encode(enc, i*obj.Dim(), (i+1)*obj.Dim())
+		}
+		enc.pad(row + layout.Stride)
+	}
+	return enc.buf
+}
+
+// pad writes zeros up to n bytes in total.
+func (enc *Encoder) pad(n int) {
+	if n > len(enc.buf) {
+		clear(enc.grow(n - len(enc.buf)))
+	}
+}
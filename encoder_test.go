@@ -0,0 +1,131 @@
+package gameblocks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+
+	"github.com/shazow/go-gameblocks/gltest"
+)
+
+// encodeObjectsBinaryWrite is the reflection-based encoder which Encoder
+// replaced, kept as a reference for tests and benchmarks.
+func encodeObjectsBinaryWrite(offset int, length int, objects ...DimSlicer) []byte {
+	buf := bytes.Buffer{}
+	for i := offset; i < length; i++ {
+		for _, obj := range objects {
+			data := obj.Slice(i*obj.Dim(), (i+1)*obj.Dim())
+			if err := binary.Write(&buf, binary.LittleEndian, data); err != nil {
+				panic(err)
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestEncoder(t *testing.T) {
+	objects := []DimSlicer{
+		NewDimSlice(3, []float32{1, 2, 3, 4, 5, 6}),
+		NewDimSlice(1, []uint8{7, 8}),
+		NewDimSlice(2, []uint16{9, 10, 11, 12}),
+		NewDimSlice(1, []uint32{13, 1 << 30}),
+	}
+
+	var enc Encoder
+	for _, r := range [][2]int{{0, 2}, {1, 2}, {2, 2}} {
+		got := enc.Encode(r[0], r[1], objects...)
+		want := encodeObjectsBinaryWrite(r[0], r[1], objects...)
+		if !bytes.Equal(got, want) {
+			t.Errorf("rows %v: got %v; want %v", r, got, want)
+		}
+	}
+
+	enc.Reset(0)
+	enc.Float32s([]float32{42})
+	enc.Uint16s([]uint16{1})
+	if got, want := enc.Bytes(), []byte{0, 0, 0x28, 0x42, 1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestEncoderAllocs(t *testing.T) {
+	vertices := NewDimSlice(vertexDim, make([]float32, 300*vertexDim))
+	normals := NewDimSlice(normalDim, make([]float32, 300*normalDim))
+
+	var enc Encoder
+	enc.Encode(0, 300, vertices, normals)
+	allocs := testing.AllocsPerRun(10, func() {
+		enc.Encode(0, 300, vertices, normals)
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations per Encode; want 0", allocs)
+	}
+}
+
+func benchmarkObjects(n int) []DimSlicer {
+	return []DimSlicer{
+		NewDimSlice(vertexDim, make([]float32, n*vertexDim)),
+		NewDimSlice(textureDim, make([]float32, n*textureDim)),
+		NewDimSlice(normalDim, make([]float32, n*normalDim)),
+	}
+}
+
+func BenchmarkEncodeObjectsBinaryWrite(b *testing.B) {
+	objects := benchmarkObjects(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encodeObjectsBinaryWrite(0, 1000, objects...)
+	}
+}
+
+func BenchmarkEncodeObjects(b *testing.B) {
+	objects := benchmarkObjects(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		EncodeObjects(0, 1000, objects...)
+	}
+}
+
+func BenchmarkEncoder(b *testing.B) {
+	objects := benchmarkObjects(1000)
+	var enc Encoder
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc.Encode(0, 1000, objects...)
+	}
+}
+
+func newBenchmarkEmitter(n int) *particleEmitter {
+	emitter := ParticleEmitter(gltest.NewContext(), mgl.Vec3{}, n, 0).(*particleEmitter)
+	for i := 0; i < n; i++ {
+		emitter.particles = append(emitter.particles, RandomParticle(mgl.Vec3{}, particleForce))
+	}
+	return emitter
+}
+
+func BenchmarkParticleBytesBinaryWrite(b *testing.B) {
+	emitter := newBenchmarkEmitter(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf := bytes.Buffer{}
+		for _, particle := range emitter.particles {
+			binary.Write(&buf, binary.LittleEndian, particle.Vertices())
+		}
+	}
+}
+
+func BenchmarkParticleBytes(b *testing.B) {
+	emitter := newBenchmarkEmitter(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		emitter.Bytes()
+	}
+}
@@ -7,39 +7,85 @@ import (
 	mgl "github.com/go-gl/mathgl/mgl32"
 	"github.com/shazow/go-gameblocks/camera"
 	"github.com/shazow/go-gameblocks/control"
+	"github.com/shazow/go-gameblocks/event"
 	"github.com/shazow/go-gameblocks/loader"
-	"golang.org/x/mobile/event/key"
 	"golang.org/x/mobile/event/size"
-	"golang.org/x/mobile/event/touch"
 	"golang.org/x/mobile/exp/app/debug"
 	"golang.org/x/mobile/exp/gl/glutil"
+	"golang.org/x/mobile/geom"
 	"golang.org/x/mobile/gl"
 )
 
 const mouseSensitivity = 0.005
 const moveSpeed = 0.1
 
+// Defaults for EngineOptions.
+const (
+	DefaultTickRate = 60
+	DefaultMaxTicks = 5
+)
+
 type Point struct {
 	X, Y float32
 }
 
+// Engine is driven by a Platform through Start. Rendering uses the
+// golang.org/x/mobile/gl API on every platform.
 type Engine interface {
 	Draw()
 	Start(glctx gl.Context) error
 	Stop()
 
 	// Event handlers
-	Touch(t touch.Event)
-	Press(t key.Event)
-	Resize(sz size.Event)
+	Touch(t event.Pointer)
+	Press(t event.Key)
+	Resize(sz event.Resize)
+
+	// Pick returns the nearest Drawable in the World under a point on
+	// screen, in pixels as in event.Pointer. See Scene.Pick.
+	Pick(x, y float32, exact bool) (Hit, bool)
+}
+
+// EngineOptions configures an Engine. Zero values are replaced by defaults.
+type EngineOptions struct {
+	// TickRate is the number of World ticks per second of game time.
+	TickRate int
+	// MaxTicks is the most World ticks run per frame when catching up.
+	MaxTicks int
+	// MaxLights is the size of the lights array in shaders.
+	MaxLights int
+	// ShaderDir, if set, is watched for changes to the sources of loaded
+	// shaders, which are reloaded with any errors shown on screen. This is
+	// for development, where it is usually "assets".
+	ShaderDir string
+	// StrictShaders logs lookups of shader attributes and uniforms which are
+	// not active, to catch misspelled names. See loader.Shader.SetStrict.
+	StrictShaders bool
 }
 
 func NewEngine(w World) Engine {
+	return NewEngineWithOptions(w, EngineOptions{})
+}
+
+func NewEngineWithOptions(w World, opts EngineOptions) Engine {
+	if opts.TickRate == 0 {
+		opts.TickRate = DefaultTickRate
+	}
+	if opts.MaxTicks == 0 {
+		opts.MaxTicks = DefaultMaxTicks
+	}
+	if opts.MaxLights == 0 {
+		opts.MaxLights = DefaultMaxLights
+	}
 	cam := camera.NewQuatCamera()
 	return &engine{
 		camera:       cam,
 		bindings:     control.DefaultBindings(),
 		world:        w,
+		step:         NewFixedStep(opts.TickRate, opts.MaxTicks),
+		maxLights:    opts.MaxLights,
+		shaderDir:    opts.ShaderDir,
+		strict:       opts.StrictShaders,
 		followOffset: mgl.Vec3{0, 7, -3},
 	}
 }
@@ -51,10 +97,18 @@ type engine struct {
 	bindings control.Bindings
 	shaders  loader.Shaders
 	textures loader.Textures
+	uniforms *Uniforms
 	world    World
 
 	started  time.Time
 	lastTick time.Time
+	step     *FixedStep
+
+	maxLights int
+	shaderDir string
+	strict    bool
+	watcher   *loader.ShaderWatcher
+	errors    errorOverlay
 
 	touchLoc     Point
 	dragOrigin   Point
@@ -64,7 +118,7 @@ type engine struct {
 	following    bool
 	followOffset mgl.Vec3
 
-	size   size.Event
+	size   size.Event // For debug.FPS
 	images *glutil.Images
 	fps    *debug.FPS
 }
@@ -77,6 +131,8 @@ func (e *engine) Start(glctx gl.Context) error {
 	e.glctx = glctx
 	e.shaders = loader.ShaderLoader(glctx)
 	e.textures = loader.TextureLoader(glctx)
+	e.uniforms = NewUniforms(glctx)
+	e.shaders.SetStrict(e.strict)
 
 	err := e.world.Start(WorldContext{
 		Bindings: e.bindings,
@@ -110,31 +166,43 @@ func (e *engine) Start(glctx gl.Context) error {
 
 	e.images = glutil.NewImages(glctx)
 	e.fps = debug.NewFPS(e.images)
+	e.errors.images = e.images
+	if e.shaderDir != "" {
+		e.watcher = loader.NewShaderWatcher(e.shaders, e.shaderDir)
+	}
 
 	log.Println("Starting: ", e.world.String())
 	return nil
 }
 
 func (e *engine) Stop() {
+	e.errors.Release()
 	e.fps.Release()
 	e.images.Release()
 
+	e.uniforms.Close()
 	e.shaders.Close()
 	e.textures.Close()
 }
 
-func (e *engine) Resize(sz size.Event) {
-	e.size = sz
+func (e *engine) Resize(sz event.Resize) {
+	e.size = size.Event{
+		WidthPx:     sz.WidthPx,
+		HeightPx:    sz.HeightPx,
+		WidthPt:     geom.Pt(float32(sz.WidthPx) / sz.PixelsPerPt),
+		HeightPt:    geom.Pt(float32(sz.HeightPx) / sz.PixelsPerPt),
+		PixelsPerPt: sz.PixelsPerPt,
+	}
 	x, y := float32(sz.WidthPx), float32(sz.HeightPx)
 	e.touchLoc.X, e.touchLoc.Y = x/2, y/2
 	e.camera.SetPerspective(0.785, x/y, 0.1, 100.0)
 }
 
-func (e *engine) Touch(t touch.Event) {
-	if t.Type == touch.TypeBegin {
+func (e *engine) Touch(t event.Pointer) {
+	if t.Type == event.PointerDown {
 		e.dragOrigin = Point{t.X, t.Y}
 		e.dragging = true
-	} else if t.Type == touch.TypeEnd {
+	} else if t.Type == event.PointerUp {
 		e.dragging = false
 		log.Println("camera=", e.camera)
 	}
@@ -146,11 +214,19 @@ func (e *engine) Touch(t touch.Event) {
 	}
 }
 
-func (e *engine) Press(t key.Event) {
+func (e *engine) Pick(x, y float32, exact bool) (Hit, bool) {
+	if e.size.WidthPx == 0 || e.size.HeightPx == 0 {
+		return Hit{}, false
+	}
+	ray := NewRay(e.camera, x, y, float32(e.size.WidthPx), float32(e.size.HeightPx))
+	return e.world.Pick(ray, exact)
+}
+
+func (e *engine) Press(t event.Key) {
 	switch t.Direction {
-	case key.DirPress:
+	case event.DirPress:
 		e.bindings.Press(t.Code)
-	case key.DirRelease:
+	case event.DirRelease:
 		e.bindings.Release(t.Code)
 	}
 }
@@ -188,25 +264,42 @@ func (e *engine) Draw() {
 		e.camera.Lerp(pos.Add(e.followOffset), pos, 0.1)
 	}
 
+	if e.watcher != nil && e.watcher.Poll() {
+		err := e.watcher.Err()
+		if err != nil {
+			log.Println("Shader reload:", err)
+		}
+		e.errors.SetError(err)
+	}
+
 	e.glctx.ClearColor(0, 0, 0, 1)
 	e.glctx.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 	e.glctx.Enable(gl.DEPTH_TEST)
 
-	if !e.paused {
-		err := e.world.Tick(interval)
-		if err != nil {
-			e.paused = true
-			e.gameover = true
+	if e.paused {
+		e.step.Reset()
+	} else {
+		for n := e.step.Advance(interval); n > 0; n-- {
+			if err := e.world.Tick(e.step.Step); err != nil {
+				e.paused = true
+				e.gameover = true
+				break
+			}
 		}
 	}
 
 	frame := FrameContext{
 		GL:     e.glctx,
 		Camera: e.camera,
+		Alpha:  e.step.Alpha(),
+
+		MaxLights: e.maxLights,
+		Uniforms:  e.uniforms,
 	}
 	e.world.Draw(frame)
 
 	e.glctx.Disable(gl.DEPTH_TEST)
 
 	e.fps.Draw(e.size)
+	e.errors.Draw(e.size)
 }
@@ -0,0 +1,57 @@
+// Package event defines the input and lifecycle events delivered to an Engine,
+// independent of the backend which produced them.
+package event
+
+import "golang.org/x/mobile/gl"
+
+// Lifecycle is sent when the drawing surface becomes available or goes away.
+type Lifecycle struct {
+	Stage Stage
+
+	// GL is the context to draw with while the stage is StageVisible.
+	GL gl.Context
+}
+
+// Stage is the state of the drawing surface.
+type Stage uint8
+
+const (
+	// StageHidden means there is no surface; GL calls must not be made.
+	StageHidden Stage = iota
+	// StageVisible means the surface and GL context are ready.
+	StageVisible
+)
+
+// Paint asks for a frame to be drawn. The platform presents it once the
+// event has been handled.
+type Paint struct{}
+
+// Resize is sent when the drawing surface changes size, and once before the
+// first Paint.
+type Resize struct {
+	WidthPx, HeightPx int
+
+	// PixelsPerPt is the number of pixels per typographic point (1/72 inch).
+	PixelsPerPt float32
+}
+
+// Pointer is a touch, or a mouse drag with the primary button held.
+type Pointer struct {
+	// X and Y are in pixels, from the top left of the surface.
+	X, Y float32
+
+	// ID is shared by all the events from one Down until its Up, to tell
+	// concurrent touches apart. IDs may be reused afterwards.
+	ID int64
+
+	Type PointerType
+}
+
+// PointerType is the phase of a Pointer event.
+type PointerType uint8
+
+const (
+	PointerDown PointerType = iota
+	PointerMove
+	PointerUp
+)
@@ -0,0 +1,173 @@
+package event
+
+import "fmt"
+
+// Key is a key press or release.
+type Key struct {
+	// Rune is the character produced by the key, or -1 if there is none.
+	Rune rune
+
+	Code      Code
+	Modifiers Modifiers
+	Direction Direction
+}
+
+func (e Key) String() string {
+	return fmt.Sprintf("event.Key{%q, %d, %d, %d}", e.Rune, e.Code, e.Modifiers, e.Direction)
+}
+
+// Direction is the direction of a Key event. Auto-repeats are DirNone.
+type Direction uint8
+
+const (
+	DirNone Direction = iota
+	DirPress
+	DirRelease
+)
+
+// Modifiers is a bitmask of held modifier keys.
+type Modifiers uint32
+
+const (
+	ModShift Modifiers = 1 << iota
+	ModControl
+	ModAlt
+	ModMeta // Command on macOS, Windows key elsewhere.
+)
+
+// Code identifies a physical key, independent of keyboard layout. Values are
+// USB HID usage IDs, the same as golang.org/x/mobile/event/key.Code.
+type Code uint32
+
+const (
+	CodeUnknown Code = 0
+
+	CodeA Code = 4
+	CodeB Code = 5
+	CodeC Code = 6
+	CodeD Code = 7
+	CodeE Code = 8
+	CodeF Code = 9
+	CodeG Code = 10
+	CodeH Code = 11
+	CodeI Code = 12
+	CodeJ Code = 13
+	CodeK Code = 14
+	CodeL Code = 15
+	CodeM Code = 16
+	CodeN Code = 17
+	CodeO Code = 18
+	CodeP Code = 19
+	CodeQ Code = 20
+	CodeR Code = 21
+	CodeS Code = 22
+	CodeT Code = 23
+	CodeU Code = 24
+	CodeV Code = 25
+	CodeW Code = 26
+	CodeX Code = 27
+	CodeY Code = 28
+	CodeZ Code = 29
+
+	Code1 Code = 30
+	Code2 Code = 31
+	Code3 Code = 32
+	Code4 Code = 33
+	Code5 Code = 34
+	Code6 Code = 35
+	Code7 Code = 36
+	Code8 Code = 37
+	Code9 Code = 38
+	Code0 Code = 39
+
+	CodeReturnEnter        Code = 40
+	CodeEscape             Code = 41
+	CodeDeleteBackspace    Code = 42
+	CodeTab                Code = 43
+	CodeSpacebar           Code = 44
+	CodeHyphenMinus        Code = 45 // -
+	CodeEqualSign          Code = 46 // =
+	CodeLeftSquareBracket  Code = 47 // [
+	CodeRightSquareBracket Code = 48 // ]
+	CodeBackslash          Code = 49 // \
+	CodeSemicolon          Code = 51 // ;
+	CodeApostrophe         Code = 52 // '
+	CodeGraveAccent        Code = 53 // `
+	CodeComma              Code = 54 // ,
+	CodeFullStop           Code = 55 // .
+	CodeSlash              Code = 56 // /
+	CodeCapsLock           Code = 57
+
+	CodeF1  Code = 58
+	CodeF2  Code = 59
+	CodeF3  Code = 60
+	CodeF4  Code = 61
+	CodeF5  Code = 62
+	CodeF6  Code = 63
+	CodeF7  Code = 64
+	CodeF8  Code = 65
+	CodeF9  Code = 66
+	CodeF10 Code = 67
+	CodeF11 Code = 68
+	CodeF12 Code = 69
+
+	CodePause         Code = 72
+	CodeInsert        Code = 73
+	CodeHome          Code = 74
+	CodePageUp        Code = 75
+	CodeDeleteForward Code = 76
+	CodeEnd           Code = 77
+	CodePageDown      Code = 78
+
+	CodeRightArrow Code = 79
+	CodeLeftArrow  Code = 80
+	CodeDownArrow  Code = 81
+	CodeUpArrow    Code = 82
+
+	CodeKeypadNumLock     Code = 83
+	CodeKeypadSlash       Code = 84 // /
+	CodeKeypadAsterisk    Code = 85 // *
+	CodeKeypadHyphenMinus Code = 86 // -
+	CodeKeypadPlusSign    Code = 87 // +
+	CodeKeypadEnter       Code = 88
+	CodeKeypad1           Code = 89
+	CodeKeypad2           Code = 90
+	CodeKeypad3           Code = 91
+	CodeKeypad4           Code = 92
+	CodeKeypad5           Code = 93
+	CodeKeypad6           Code = 94
+	CodeKeypad7           Code = 95
+	CodeKeypad8           Code = 96
+	CodeKeypad9           Code = 97
+	CodeKeypad0           Code = 98
+	CodeKeypadFullStop    Code = 99  // .
+	CodeKeypadEqualSign   Code = 103 // =
+
+	CodeF13 Code = 104
+	CodeF14 Code = 105
+	CodeF15 Code = 106
+	CodeF16 Code = 107
+	CodeF17 Code = 108
+	CodeF18 Code = 109
+	CodeF19 Code = 110
+	CodeF20 Code = 111
+	CodeF21 Code = 112
+	CodeF22 Code = 113
+	CodeF23 Code = 114
+	CodeF24 Code = 115
+
+	CodeHelp Code = 117
+
+	CodeMute       Code = 127
+	CodeVolumeUp   Code = 128
+	CodeVolumeDown Code = 129
+
+	CodeLeftControl  Code = 224
+	CodeLeftShift    Code = 225
+	CodeLeftAlt      Code = 226
+	CodeLeftGUI      Code = 227
+	CodeRightControl Code = 228
+	CodeRightShift   Code = 229
+	CodeRightAlt     Code = 230
+	CodeRightGUI     Code = 231
+)
@@ -0,0 +1,404 @@
+package gltest
+
+import "golang.org/x/mobile/gl"
+
+// Calls below have no side effects other than being recorded. Queries return
+// zero values.
+
+func (c *Context) ActiveTexture(texture gl.Enum) {
+	c.record("ActiveTexture", texture)
+}
+
+func (c *Context) BindAttribLocation(p gl.Program, a gl.Attrib, name string) {
+	c.record("BindAttribLocation", p, a, name)
+}
+
+func (c *Context) BindFramebuffer(target gl.Enum, fb gl.Framebuffer) {
+	c.record("BindFramebuffer", target, fb)
+}
+
+func (c *Context) BindRenderbuffer(target gl.Enum, rb gl.Renderbuffer) {
+	c.record("BindRenderbuffer", target, rb)
+}
+
+func (c *Context) BindTexture(target gl.Enum, t gl.Texture) {
+	c.record("BindTexture", target, t)
+}
+
+func (c *Context) BindVertexArray(rb gl.VertexArray) {
+	c.record("BindVertexArray", rb)
+}
+
+func (c *Context) BlendColor(red, green, blue, alpha float32) {
+	c.record("BlendColor", red, green, blue, alpha)
+}
+
+func (c *Context) BlendEquation(mode gl.Enum) {
+	c.record("BlendEquation", mode)
+}
+
+func (c *Context) BlendEquationSeparate(modeRGB, modeAlpha gl.Enum) {
+	c.record("BlendEquationSeparate", modeRGB, modeAlpha)
+}
+
+func (c *Context) BlendFunc(sfactor, dfactor gl.Enum) {
+	c.record("BlendFunc", sfactor, dfactor)
+}
+
+func (c *Context) BlendFuncSeparate(sfactorRGB, dfactorRGB, sfactorAlpha, dfactorAlpha gl.Enum) {
+	c.record("BlendFuncSeparate", sfactorRGB, dfactorRGB, sfactorAlpha, dfactorAlpha)
+}
+
+func (c *Context) Clear(mask gl.Enum) {
+	c.record("Clear", mask)
+}
+
+func (c *Context) ClearColor(red, green, blue, alpha float32) {
+	c.record("ClearColor", red, green, blue, alpha)
+}
+
+func (c *Context) ClearDepthf(d float32) {
+	c.record("ClearDepthf", d)
+}
+
+func (c *Context) ClearStencil(s int) {
+	c.record("ClearStencil", s)
+}
+
+func (c *Context) ColorMask(red, green, blue, alpha bool) {
+	c.record("ColorMask", red, green, blue, alpha)
+}
+
+func (c *Context) CompressedTexImage2D(target gl.Enum, level int, internalformat gl.Enum, width, height, border int, data []byte) {
+	c.record("CompressedTexImage2D", target, level, internalformat, width, height, border, data)
+}
+
+func (c *Context) CompressedTexSubImage2D(target gl.Enum, level, xoffset, yoffset, width, height int, format gl.Enum, data []byte) {
+	c.record("CompressedTexSubImage2D", target, level, xoffset, yoffset, width, height, format, data)
+}
+
+func (c *Context) CopyTexImage2D(target gl.Enum, level int, internalformat gl.Enum, x, y, width, height, border int) {
+	c.record("CopyTexImage2D", target, level, internalformat, x, y, width, height, border)
+}
+
+func (c *Context) CopyTexSubImage2D(target gl.Enum, level, xoffset, yoffset, x, y, width, height int) {
+	c.record("CopyTexSubImage2D", target, level, xoffset, yoffset, x, y, width, height)
+}
+
+func (c *Context) CullFace(mode gl.Enum) {
+	c.record("CullFace", mode)
+}
+
+func (c *Context) DepthFunc(fn gl.Enum) {
+	c.record("DepthFunc", fn)
+}
+
+func (c *Context) DepthMask(flag bool) {
+	c.record("DepthMask", flag)
+}
+
+func (c *Context) DepthRangef(n, f float32) {
+	c.record("DepthRangef", n, f)
+}
+
+func (c *Context) DisableVertexAttribArray(a gl.Attrib) {
+	c.record("DisableVertexAttribArray", a)
+}
+
+func (c *Context) DrawArrays(mode gl.Enum, first, count int) {
+	c.record("DrawArrays", mode, first, count)
+}
+
+func (c *Context) DrawElements(mode gl.Enum, count int, ty gl.Enum, offset int) {
+	c.record("DrawElements", mode, count, ty, offset)
+}
+
+func (c *Context) EnableVertexAttribArray(a gl.Attrib) {
+	c.record("EnableVertexAttribArray", a)
+}
+
+func (c *Context) Finish() {
+	c.record("Finish")
+}
+
+func (c *Context) Flush() {
+	c.record("Flush")
+}
+
+func (c *Context) FramebufferRenderbuffer(target, attachment, rbTarget gl.Enum, rb gl.Renderbuffer) {
+	c.record("FramebufferRenderbuffer", target, attachment, rbTarget, rb)
+}
+
+func (c *Context) FramebufferTexture2D(target, attachment, texTarget gl.Enum, t gl.Texture, level int) {
+	c.record("FramebufferTexture2D", target, attachment, texTarget, t, level)
+}
+
+func (c *Context) FrontFace(mode gl.Enum) {
+	c.record("FrontFace", mode)
+}
+
+func (c *Context) GenerateMipmap(target gl.Enum) {
+	c.record("GenerateMipmap", target)
+}
+
+func (c *Context) GetBooleanv(dst []bool, pname gl.Enum) {
+	c.record("GetBooleanv", dst, pname)
+}
+
+func (c *Context) GetFloatv(dst []float32, pname gl.Enum) {
+	c.record("GetFloatv", dst, pname)
+}
+
+func (c *Context) GetBufferParameteri(target, value gl.Enum) int {
+	c.record("GetBufferParameteri", target, value)
+	return 0
+}
+
+func (c *Context) GetFramebufferAttachmentParameteri(target, attachment, pname gl.Enum) int {
+	c.record("GetFramebufferAttachmentParameteri", target, attachment, pname)
+	return 0
+}
+
+func (c *Context) GetRenderbufferParameteri(target, pname gl.Enum) int {
+	c.record("GetRenderbufferParameteri", target, pname)
+	return 0
+}
+
+func (c *Context) GetShaderPrecisionFormat(shadertype, precisiontype gl.Enum) (rangeLow, rangeHigh, precision int) {
+	c.record("GetShaderPrecisionFormat", shadertype, precisiontype)
+	return
+}
+
+func (c *Context) GetTexParameterfv(dst []float32, target, pname gl.Enum) {
+	c.record("GetTexParameterfv", dst, target, pname)
+}
+
+func (c *Context) GetTexParameteriv(dst []int32, target, pname gl.Enum) {
+	c.record("GetTexParameteriv", dst, target, pname)
+}
+
+func (c *Context) GetUniformfv(dst []float32, src gl.Uniform, p gl.Program) {
+	c.record("GetUniformfv", dst, src, p)
+}
+
+func (c *Context) GetUniformiv(dst []int32, src gl.Uniform, p gl.Program) {
+	c.record("GetUniformiv", dst, src, p)
+}
+
+func (c *Context) GetVertexAttribf(src gl.Attrib, pname gl.Enum) float32 {
+	c.record("GetVertexAttribf", src, pname)
+	return 0
+}
+
+func (c *Context) GetVertexAttribfv(dst []float32, src gl.Attrib, pname gl.Enum) {
+	c.record("GetVertexAttribfv", dst, src, pname)
+}
+
+func (c *Context) GetVertexAttribi(src gl.Attrib, pname gl.Enum) int32 {
+	c.record("GetVertexAttribi", src, pname)
+	return 0
+}
+
+func (c *Context) GetVertexAttribiv(dst []int32, src gl.Attrib, pname gl.Enum) {
+	c.record("GetVertexAttribiv", dst, src, pname)
+}
+
+func (c *Context) Hint(target, mode gl.Enum) {
+	c.record("Hint", target, mode)
+}
+
+func (c *Context) LineWidth(width float32) {
+	c.record("LineWidth", width)
+}
+
+func (c *Context) PixelStorei(pname gl.Enum, param int32) {
+	c.record("PixelStorei", pname, param)
+}
+
+func (c *Context) PolygonOffset(factor, units float32) {
+	c.record("PolygonOffset", factor, units)
+}
+
+func (c *Context) ReadPixels(dst []byte, x, y, width, height int, format, ty gl.Enum) {
+	c.record("ReadPixels", dst, x, y, width, height, format, ty)
+}
+
+func (c *Context) ReleaseShaderCompiler() {
+	c.record("ReleaseShaderCompiler")
+}
+
+func (c *Context) RenderbufferStorage(target, internalFormat gl.Enum, width, height int) {
+	c.record("RenderbufferStorage", target, internalFormat, width, height)
+}
+
+func (c *Context) SampleCoverage(value float32, invert bool) {
+	c.record("SampleCoverage", value, invert)
+}
+
+func (c *Context) Scissor(x, y, width, height int32) {
+	c.record("Scissor", x, y, width, height)
+}
+
+func (c *Context) StencilFunc(fn gl.Enum, ref int, mask uint32) {
+	c.record("StencilFunc", fn, ref, mask)
+}
+
+func (c *Context) StencilFuncSeparate(face, fn gl.Enum, ref int, mask uint32) {
+	c.record("StencilFuncSeparate", face, fn, ref, mask)
+}
+
+func (c *Context) StencilMask(mask uint32) {
+	c.record("StencilMask", mask)
+}
+
+func (c *Context) StencilMaskSeparate(face gl.Enum, mask uint32) {
+	c.record("StencilMaskSeparate", face, mask)
+}
+
+func (c *Context) StencilOp(fail, zfail, zpass gl.Enum) {
+	c.record("StencilOp", fail, zfail, zpass)
+}
+
+func (c *Context) StencilOpSeparate(face, sfail, dpfail, dppass gl.Enum) {
+	c.record("StencilOpSeparate", face, sfail, dpfail, dppass)
+}
+
+func (c *Context) TexSubImage2D(target gl.Enum, level int, x, y, width, height int, format, ty gl.Enum, data []byte) {
+	c.record("TexSubImage2D", target, level, x, y, width, height, format, ty, data)
+}
+
+func (c *Context) TexParameterf(target, pname gl.Enum, param float32) {
+	c.record("TexParameterf", target, pname, param)
+}
+
+func (c *Context) TexParameterfv(target, pname gl.Enum, params []float32) {
+	c.record("TexParameterfv", target, pname, params)
+}
+
+func (c *Context) TexParameteri(target, pname gl.Enum, param int) {
+	c.record("TexParameteri", target, pname, param)
+}
+
+func (c *Context) TexParameteriv(target, pname gl.Enum, params []int32) {
+	c.record("TexParameteriv", target, pname, params)
+}
+
+func (c *Context) Uniform1f(dst gl.Uniform, v float32) {
+	c.record("Uniform1f", dst, v)
+}
+
+func (c *Context) Uniform1fv(dst gl.Uniform, src []float32) {
+	c.record("Uniform1fv", dst, src)
+}
+
+func (c *Context) Uniform1i(dst gl.Uniform, v int) {
+	c.record("Uniform1i", dst, v)
+}
+
+func (c *Context) Uniform1iv(dst gl.Uniform, src []int32) {
+	c.record("Uniform1iv", dst, src)
+}
+
+func (c *Context) Uniform2f(dst gl.Uniform, v0, v1 float32) {
+	c.record("Uniform2f", dst, v0, v1)
+}
+
+func (c *Context) Uniform2fv(dst gl.Uniform, src []float32) {
+	c.record("Uniform2fv", dst, src)
+}
+
+func (c *Context) Uniform2i(dst gl.Uniform, v0, v1 int) {
+	c.record("Uniform2i", dst, v0, v1)
+}
+
+func (c *Context) Uniform2iv(dst gl.Uniform, src []int32) {
+	c.record("Uniform2iv", dst, src)
+}
+
+func (c *Context) Uniform3f(dst gl.Uniform, v0, v1, v2 float32) {
+	c.record("Uniform3f", dst, v0, v1, v2)
+}
+
+func (c *Context) Uniform3fv(dst gl.Uniform, src []float32) {
+	c.record("Uniform3fv", dst, src)
+}
+
+func (c *Context) Uniform3i(dst gl.Uniform, v0, v1, v2 int32) {
+	c.record("Uniform3i", dst, v0, v1, v2)
+}
+
+func (c *Context) Uniform3iv(dst gl.Uniform, src []int32) {
+	c.record("Uniform3iv", dst, src)
+}
+
+func (c *Context) Uniform4f(dst gl.Uniform, v0, v1, v2, v3 float32) {
+	c.record("Uniform4f", dst, v0, v1, v2, v3)
+}
+
+func (c *Context) Uniform4fv(dst gl.Uniform, src []float32) {
+	c.record("Uniform4fv", dst, src)
+}
+
+func (c *Context) Uniform4i(dst gl.Uniform, v0, v1, v2, v3 int32) {
+	c.record("Uniform4i", dst, v0, v1, v2, v3)
+}
+
+func (c *Context) Uniform4iv(dst gl.Uniform, src []int32) {
+	c.record("Uniform4iv", dst, src)
+}
+
+func (c *Context) UniformMatrix2fv(dst gl.Uniform, src []float32) {
+	c.record("UniformMatrix2fv", dst, src)
+}
+
+func (c *Context) UniformMatrix3fv(dst gl.Uniform, src []float32) {
+	c.record("UniformMatrix3fv", dst, src)
+}
+
+func (c *Context) UniformMatrix4fv(dst gl.Uniform, src []float32) {
+	c.record("UniformMatrix4fv", dst, src)
+}
+
+func (c *Context) ValidateProgram(p gl.Program) {
+	c.record("ValidateProgram", p)
+}
+
+func (c *Context) VertexAttrib1f(dst gl.Attrib, x float32) {
+	c.record("VertexAttrib1f", dst, x)
+}
+
+func (c *Context) VertexAttrib1fv(dst gl.Attrib, src []float32) {
+	c.record("VertexAttrib1fv", dst, src)
+}
+
+func (c *Context) VertexAttrib2f(dst gl.Attrib, x, y float32) {
+	c.record("VertexAttrib2f", dst, x, y)
+}
+
+func (c *Context) VertexAttrib2fv(dst gl.Attrib, src []float32) {
+	c.record("VertexAttrib2fv", dst, src)
+}
+
+func (c *Context) VertexAttrib3f(dst gl.Attrib, x, y, z float32) {
+	c.record("VertexAttrib3f", dst, x, y, z)
+}
+
+func (c *Context) VertexAttrib3fv(dst gl.Attrib, src []float32) {
+	c.record("VertexAttrib3fv", dst, src)
+}
+
+func (c *Context) VertexAttrib4f(dst gl.Attrib, x, y, z, w float32) {
+	c.record("VertexAttrib4f", dst, x, y, z, w)
+}
+
+func (c *Context) VertexAttrib4fv(dst gl.Attrib, src []float32) {
+	c.record("VertexAttrib4fv", dst, src)
+}
+
+func (c *Context) VertexAttribPointer(dst gl.Attrib, size int, ty gl.Enum, normalized bool, stride, offset int) {
+	c.record("VertexAttribPointer", dst, size, ty, normalized, stride, offset)
+}
+
+func (c *Context) Viewport(x, y, width, height int) {
+	c.record("Viewport", x, y, width, height)
+}
@@ -0,0 +1,747 @@
+// Package gltest provides a headless gl.Context which records every call made
+// to it, for testing rendering code without a GPU.
+package gltest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mobile/gl"
+)
+
+// Call is a single recorded gl.Context method call.
+type Call struct {
+	Name string
+	Args []interface{}
+}
+
+func (c Call) String() string {
+	args := make([]string, 0, len(c.Args))
+	for _, arg := range c.Args {
+		if b, ok := arg.([]byte); ok {
+			args = append(args, fmt.Sprintf("<%d bytes>", len(b)))
+			continue
+		}
+		args = append(args, fmt.Sprint(arg))
+	}
+	return fmt.Sprintf("%s(%s)", c.Name, strings.Join(args, ", "))
+}
+
+type object struct {
+	kind string
+	id   uint32
+}
+
+// Variable is an active attribute or uniform of a linked program.
+type Variable struct {
+	Name string
+	Size int
+	Type gl.Enum
+}
+
+type program struct {
+	shaders  []gl.Shader
+	linked   bool
+	declared bool // Linked from sources which declared variables.
+	attribs  []Variable
+	uniforms []Variable
+	blocks   []string // Uniform block names, by index
+	// Locations handed out by name, either parsed or assigned on lookup.
+	attribLocs  map[string]uint
+	uniformLocs map[string]int32
+	nextUniform int32
+}
+
+type shader struct {
+	ty       gl.Enum
+	src      string
+	compiled bool
+	log      string
+}
+
+// NewContext returns an empty recording Context.
+func NewContext() *Context {
+	return &Context{
+		Integers: map[gl.Enum]int{},
+		Strings:  map[gl.Enum]string{gl.VERSION: "OpenGL ES 2.0 gltest"},
+
+		live:     map[object]bool{},
+		buffers:  map[uint32][]byte{},
+		bound:    map[gl.Enum]gl.Buffer{},
+		enabled:  map[gl.Enum]bool{},
+		programs: map[uint32]*program{},
+		shaders:  map[uint32]*shader{},
+	}
+}
+
+// Context is a fake gl.Context. It hands out sequential handles for buffers,
+// programs, shaders, textures and so on, keeps the bytes uploaded to each
+// buffer, and records every method call in Calls.
+//
+// Programs linked from shaders whose sources declare attributes and uniforms
+// only resolve those names; lookups of anything else return -1, like GL.
+// Programs without declarations hand out a new location for every name.
+type Context struct {
+	// Calls is every call made to the context, in order.
+	Calls []Call
+
+	// CompileError is called for each compiled shader source. If it returns
+	// a non-empty string, the compile fails with that string as the log.
+	CompileError func(src string) string
+
+	// Integers and Strings are returned by GetInteger and GetString. The
+	// VERSION string is OpenGL ES 2.0, or 3.0 for a Context3.
+	Integers map[gl.Enum]int
+	Strings  map[gl.Enum]string
+
+	nextID   uint32
+	live     map[object]bool
+	buffers  map[uint32][]byte
+	bound    map[gl.Enum]gl.Buffer
+	enabled  map[gl.Enum]bool
+	programs map[uint32]*program
+	shaders  map[uint32]*shader
+	current  gl.Program
+}
+
+var _ gl.Context = &Context{}
+
+// Context3 is a Context which also satisfies gl.Context3, for exercising
+// OpenGL ES 3 code paths.
+type Context3 struct {
+	*Context
+}
+
+// NewContext3 returns an empty recording Context3.
+func NewContext3() Context3 {
+	c := NewContext()
+	c.Strings[gl.VERSION] = "OpenGL ES 3.0 gltest"
+	return Context3{c}
+}
+
+var _ gl.Context3 = Context3{}
+
+// VertexAttribDivisor, DrawArraysInstanced and DrawElementsInstanced are the
+// instancing entry points of OpenGL ES 3, which gl.Context3 does not expose.
+
+func (c Context3) VertexAttribDivisor(index gl.Attrib, divisor int) {
+	c.record("VertexAttribDivisor", index, divisor)
+}
+
+func (c Context3) DrawArraysInstanced(mode gl.Enum, first, count, instances int) {
+	c.record("DrawArraysInstanced", mode, first, count, instances)
+}
+
+func (c Context3) DrawElementsInstanced(mode gl.Enum, count int, ty gl.Enum, offset, instances int) {
+	c.record("DrawElementsInstanced", mode, count, ty, offset, instances)
+}
+
+// GetUniformBlockIndex, UniformBlockBinding and BindBufferBase are the
+// uniform buffer entry points of OpenGL ES 3, which gl.Context3 does not
+// expose. Blocks are found in the sources of linked programs.
+
+func (c Context3) GetUniformBlockIndex(p gl.Program, name string) uint32 {
+	c.record("GetUniformBlockIndex", p, name)
+	if prog, ok := c.programs[p.Value]; ok {
+		for i, block := range prog.blocks {
+			if block == name {
+				return uint32(i)
+			}
+		}
+	}
+	return gl.INVALID_INDEX
+}
+
+func (c Context3) UniformBlockBinding(p gl.Program, index, binding uint32) {
+	c.record("UniformBlockBinding", p, index, binding)
+}
+
+func (c Context3) BindBufferBase(target gl.Enum, index uint32, b gl.Buffer) {
+	c.record("BindBufferBase", target, index, b)
+	c.bound[target] = b
+}
+
+func (c Context3) BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int, mask uint, filter gl.Enum) {
+	c.record("BlitFramebuffer", srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, mask, filter)
+}
+
+func (c *Context) record(name string, args ...interface{}) {
+	for i, arg := range args {
+		// Copy slices so later mutation by the caller doesn't change history.
+		switch v := arg.(type) {
+		case []byte:
+			args[i] = append([]byte(nil), v...)
+		case []float32:
+			args[i] = append([]float32(nil), v...)
+		case []int32:
+			args[i] = append([]int32(nil), v...)
+		}
+	}
+	c.Calls = append(c.Calls, Call{Name: name, Args: args})
+}
+
+func (c *Context) create(kind string) uint32 {
+	c.nextID++
+	c.live[object{kind, c.nextID}] = true
+	return c.nextID
+}
+
+// Reset clears the recorded calls, but keeps all other state.
+func (c *Context) Reset() {
+	c.Calls = nil
+}
+
+// Names returns the names of the recorded calls, in order.
+func (c *Context) Names() []string {
+	r := make([]string, 0, len(c.Calls))
+	for _, call := range c.Calls {
+		r = append(r, call.Name)
+	}
+	return r
+}
+
+// Filter returns the recorded calls with any of the given names, in order.
+func (c *Context) Filter(names ...string) []Call {
+	r := []Call{}
+	for _, call := range c.Calls {
+		for _, name := range names {
+			if call.Name == name {
+				r = append(r, call)
+				break
+			}
+		}
+	}
+	return r
+}
+
+// BufferBytes returns the current contents of a buffer, as uploaded through
+// BufferInit, BufferData and BufferSubData.
+func (c *Context) BufferBytes(b gl.Buffer) []byte {
+	return c.buffers[b.Value]
+}
+
+// BoundBuffer returns the buffer bound to target.
+func (c *Context) BoundBuffer(target gl.Enum) gl.Buffer {
+	return c.bound[target]
+}
+
+// CurrentProgram returns the program passed to the last UseProgram.
+func (c *Context) CurrentProgram() gl.Program {
+	return c.current
+}
+
+// Handles
+
+func (c *Context) CreateBuffer() gl.Buffer {
+	b := gl.Buffer{Value: c.create("buffer")}
+	c.record("CreateBuffer")
+	return b
+}
+
+func (c *Context) CreateFramebuffer() gl.Framebuffer {
+	fb := gl.Framebuffer{Value: c.create("framebuffer")}
+	c.record("CreateFramebuffer")
+	return fb
+}
+
+func (c *Context) CreateRenderbuffer() gl.Renderbuffer {
+	rb := gl.Renderbuffer{Value: c.create("renderbuffer")}
+	c.record("CreateRenderbuffer")
+	return rb
+}
+
+func (c *Context) CreateTexture() gl.Texture {
+	t := gl.Texture{Value: c.create("texture")}
+	c.record("CreateTexture")
+	return t
+}
+
+func (c *Context) CreateVertexArray() gl.VertexArray {
+	va := gl.VertexArray{Value: c.create("vertexarray")}
+	c.record("CreateVertexArray")
+	return va
+}
+
+func (c *Context) CreateProgram() gl.Program {
+	id := c.create("program")
+	c.programs[id] = &program{
+		attribLocs:  map[string]uint{},
+		uniformLocs: map[string]int32{},
+	}
+	c.record("CreateProgram")
+	return gl.Program{Value: id}
+}
+
+func (c *Context) CreateShader(ty gl.Enum) gl.Shader {
+	id := c.create("shader")
+	c.shaders[id] = &shader{ty: ty}
+	c.record("CreateShader", ty)
+	return gl.Shader{Value: id}
+}
+
+func (c *Context) DeleteBuffer(v gl.Buffer) {
+	delete(c.live, object{"buffer", v.Value})
+	delete(c.buffers, v.Value)
+	c.record("DeleteBuffer", v)
+}
+
+func (c *Context) DeleteFramebuffer(v gl.Framebuffer) {
+	delete(c.live, object{"framebuffer", v.Value})
+	c.record("DeleteFramebuffer", v)
+}
+
+func (c *Context) DeleteRenderbuffer(v gl.Renderbuffer) {
+	delete(c.live, object{"renderbuffer", v.Value})
+	c.record("DeleteRenderbuffer", v)
+}
+
+func (c *Context) DeleteTexture(v gl.Texture) {
+	delete(c.live, object{"texture", v.Value})
+	c.record("DeleteTexture", v)
+}
+
+func (c *Context) DeleteVertexArray(v gl.VertexArray) {
+	delete(c.live, object{"vertexarray", v.Value})
+	c.record("DeleteVertexArray", v)
+}
+
+func (c *Context) DeleteProgram(p gl.Program) {
+	delete(c.live, object{"program", p.Value})
+	delete(c.programs, p.Value)
+	c.record("DeleteProgram", p)
+}
+
+func (c *Context) DeleteShader(s gl.Shader) {
+	delete(c.live, object{"shader", s.Value})
+	c.record("DeleteShader", s)
+}
+
+func (c *Context) IsBuffer(b gl.Buffer) bool {
+	c.record("IsBuffer", b)
+	return c.live[object{"buffer", b.Value}]
+}
+
+func (c *Context) IsFramebuffer(fb gl.Framebuffer) bool {
+	c.record("IsFramebuffer", fb)
+	return c.live[object{"framebuffer", fb.Value}]
+}
+
+func (c *Context) IsRenderbuffer(rb gl.Renderbuffer) bool {
+	c.record("IsRenderbuffer", rb)
+	return c.live[object{"renderbuffer", rb.Value}]
+}
+
+func (c *Context) IsTexture(t gl.Texture) bool {
+	c.record("IsTexture", t)
+	return c.live[object{"texture", t.Value}]
+}
+
+func (c *Context) IsProgram(p gl.Program) bool {
+	c.record("IsProgram", p)
+	return c.live[object{"program", p.Value}]
+}
+
+func (c *Context) IsShader(s gl.Shader) bool {
+	c.record("IsShader", s)
+	return c.live[object{"shader", s.Value}]
+}
+
+// Buffers
+
+func (c *Context) BindBuffer(target gl.Enum, b gl.Buffer) {
+	c.bound[target] = b
+	c.record("BindBuffer", target, b)
+}
+
+func (c *Context) BufferInit(target gl.Enum, size int, usage gl.Enum) {
+	c.buffers[c.bound[target].Value] = make([]byte, size)
+	c.record("BufferInit", target, size, usage)
+}
+
+func (c *Context) BufferData(target gl.Enum, src []byte, usage gl.Enum) {
+	c.buffers[c.bound[target].Value] = append([]byte(nil), src...)
+	c.record("BufferData", target, src, usage)
+}
+
+func (c *Context) BufferSubData(target gl.Enum, offset int, data []byte) {
+	id := c.bound[target].Value
+	buf := c.buffers[id]
+	if end := offset + len(data); end > len(buf) {
+		buf = append(buf, make([]byte, end-len(buf))...)
+	}
+	copy(buf[offset:], data)
+	c.buffers[id] = buf
+	c.record("BufferSubData", target, offset, data)
+}
+
+// Capabilities and queries
+
+func (c *Context) Enable(cap gl.Enum) {
+	c.enabled[cap] = true
+	c.record("Enable", cap)
+}
+
+func (c *Context) Disable(cap gl.Enum) {
+	c.enabled[cap] = false
+	c.record("Disable", cap)
+}
+
+func (c *Context) IsEnabled(cap gl.Enum) bool {
+	c.record("IsEnabled", cap)
+	return c.enabled[cap]
+}
+
+func (c *Context) GetError() gl.Enum {
+	c.record("GetError")
+	return gl.NO_ERROR
+}
+
+func (c *Context) CheckFramebufferStatus(target gl.Enum) gl.Enum {
+	c.record("CheckFramebufferStatus", target)
+	return gl.FRAMEBUFFER_COMPLETE
+}
+
+func (c *Context) GetInteger(pname gl.Enum) int {
+	c.record("GetInteger", pname)
+	return c.Integers[pname]
+}
+
+func (c *Context) GetIntegerv(dst []int32, pname gl.Enum) {
+	if len(dst) > 0 {
+		dst[0] = int32(c.Integers[pname])
+	}
+	c.record("GetIntegerv", dst, pname)
+}
+
+func (c *Context) GetString(pname gl.Enum) string {
+	c.record("GetString", pname)
+	return c.Strings[pname]
+}
+
+// Textures
+
+// TexImage2D records the upload; the pixels are kept in the call arguments.
+func (c *Context) TexImage2D(target gl.Enum, level int, width, height int, format gl.Enum, ty gl.Enum, data []byte) {
+	c.record("TexImage2D", target, level, width, height, format, ty, data)
+}
+
+// Shaders and programs
+
+func (c *Context) ShaderSource(s gl.Shader, src string) {
+	if sh, ok := c.shaders[s.Value]; ok {
+		sh.src = src
+	}
+	c.record("ShaderSource", s, src)
+}
+
+func (c *Context) GetShaderSource(s gl.Shader) string {
+	c.record("GetShaderSource", s)
+	if sh, ok := c.shaders[s.Value]; ok {
+		return sh.src
+	}
+	return ""
+}
+
+func (c *Context) CompileShader(s gl.Shader) {
+	if sh, ok := c.shaders[s.Value]; ok {
+		sh.log = ""
+		if c.CompileError != nil {
+			sh.log = c.CompileError(sh.src)
+		}
+		sh.compiled = sh.log == ""
+	}
+	c.record("CompileShader", s)
+}
+
+func (c *Context) GetShaderi(s gl.Shader, pname gl.Enum) int {
+	c.record("GetShaderi", s, pname)
+	sh, ok := c.shaders[s.Value]
+	if !ok {
+		return 0
+	}
+	switch pname {
+	case gl.COMPILE_STATUS:
+		if sh.compiled {
+			return 1
+		}
+	case gl.SHADER_TYPE:
+		return int(sh.ty)
+	case gl.SHADER_SOURCE_LENGTH:
+		return len(sh.src)
+	case gl.INFO_LOG_LENGTH:
+		return len(sh.log)
+	}
+	return 0
+}
+
+func (c *Context) GetShaderInfoLog(s gl.Shader) string {
+	c.record("GetShaderInfoLog", s)
+	if sh, ok := c.shaders[s.Value]; ok {
+		return sh.log
+	}
+	return ""
+}
+
+func (c *Context) AttachShader(p gl.Program, s gl.Shader) {
+	if prog, ok := c.programs[p.Value]; ok {
+		prog.shaders = append(prog.shaders, s)
+	}
+	c.record("AttachShader", p, s)
+}
+
+func (c *Context) DetachShader(p gl.Program, s gl.Shader) {
+	if prog, ok := c.programs[p.Value]; ok {
+		for i, attached := range prog.shaders {
+			if attached == s {
+				prog.shaders = append(prog.shaders[:i], prog.shaders[i+1:]...)
+				break
+			}
+		}
+	}
+	c.record("DetachShader", p, s)
+}
+
+func (c *Context) GetAttachedShaders(p gl.Program) []gl.Shader {
+	c.record("GetAttachedShaders", p)
+	if prog, ok := c.programs[p.Value]; ok {
+		return append([]gl.Shader(nil), prog.shaders...)
+	}
+	return nil
+}
+
+func (c *Context) LinkProgram(p gl.Program) {
+	c.record("LinkProgram", p)
+	prog, ok := c.programs[p.Value]
+	if !ok {
+		return
+	}
+
+	prog.linked = true
+	prog.attribs, prog.uniforms = nil, nil
+	prog.attribLocs, prog.uniformLocs = map[string]uint{}, map[string]int32{}
+	prog.nextUniform = 0
+
+	prog.blocks = nil
+	seen, seenBlocks := map[string]bool{}, map[string]bool{}
+	for _, s := range prog.shaders {
+		sh, ok := c.shaders[s.Value]
+		if !ok || !sh.compiled {
+			prog.linked = false
+			continue
+		}
+		attribs, uniforms := parseDeclarations(sh.src, sh.ty == gl.VERTEX_SHADER)
+		prog.attribs = append(prog.attribs, attribs...)
+		for _, m := range reBlock.FindAllStringSubmatch(sh.src, -1) {
+			if !seenBlocks[m[1]] {
+				seenBlocks[m[1]] = true
+				prog.blocks = append(prog.blocks, m[1])
+			}
+		}
+		for _, u := range uniforms {
+			if !seen[u.Name] {
+				seen[u.Name] = true
+				prog.uniforms = append(prog.uniforms, u)
+			}
+		}
+	}
+	prog.declared = len(prog.attribs)+len(prog.uniforms) > 0
+
+	// Matrix attributes take a location per column, like GL.
+	var loc uint
+	for _, a := range prog.attribs {
+		prog.attribLocs[a.Name] = loc
+		switch a.Type {
+		case gl.FLOAT_MAT2:
+			loc += 2
+		case gl.FLOAT_MAT3:
+			loc += 3
+		case gl.FLOAT_MAT4:
+			loc += 4
+		default:
+			loc++
+		}
+	}
+	for _, u := range prog.uniforms {
+		base := strings.TrimSuffix(u.Name, "[0]")
+		prog.uniformLocs[base] = prog.nextUniform
+		for i := 0; i < u.Size; i++ {
+			prog.uniformLocs[fmt.Sprintf("%s[%d]", base, i)] = prog.nextUniform
+			prog.nextUniform++
+		}
+	}
+}
+
+func (c *Context) GetProgrami(p gl.Program, pname gl.Enum) int {
+	c.record("GetProgrami", p, pname)
+	prog, ok := c.programs[p.Value]
+	if !ok {
+		return 0
+	}
+	switch pname {
+	case gl.LINK_STATUS:
+		if prog.linked {
+			return 1
+		}
+	case gl.ATTACHED_SHADERS:
+		return len(prog.shaders)
+	case gl.ACTIVE_ATTRIBUTES:
+		return len(prog.attribs)
+	case gl.ACTIVE_UNIFORMS:
+		return len(prog.uniforms)
+	}
+	return 0
+}
+
+func (c *Context) GetProgramInfoLog(p gl.Program) string {
+	c.record("GetProgramInfoLog", p)
+	if prog, ok := c.programs[p.Value]; ok && !prog.linked {
+		return "gltest: attached shader failed to compile"
+	}
+	return ""
+}
+
+func (c *Context) UseProgram(p gl.Program) {
+	c.current = p
+	c.record("UseProgram", p)
+}
+
+func (c *Context) GetAttribLocation(p gl.Program, name string) gl.Attrib {
+	c.record("GetAttribLocation", p, name)
+	prog, ok := c.programs[p.Value]
+	if !ok {
+		return gl.Attrib{Value: ^uint(0)}
+	}
+	if loc, ok := prog.attribLocs[name]; ok {
+		return gl.Attrib{Value: loc}
+	}
+	if prog.declared {
+		return gl.Attrib{Value: ^uint(0)}
+	}
+	loc := uint(len(prog.attribLocs))
+	prog.attribLocs[name] = loc
+	return gl.Attrib{Value: loc}
+}
+
+func (c *Context) GetUniformLocation(p gl.Program, name string) gl.Uniform {
+	c.record("GetUniformLocation", p, name)
+	prog, ok := c.programs[p.Value]
+	if !ok {
+		return gl.Uniform{Value: -1}
+	}
+	if loc, ok := prog.uniformLocs[name]; ok {
+		return gl.Uniform{Value: loc}
+	}
+	if prog.declared {
+		return gl.Uniform{Value: -1}
+	}
+	loc := prog.nextUniform
+	prog.nextUniform++
+	prog.uniformLocs[name] = loc
+	return gl.Uniform{Value: loc}
+}
+
+func (c *Context) GetActiveAttrib(p gl.Program, index uint32) (name string, size int, ty gl.Enum) {
+	c.record("GetActiveAttrib", p, index)
+	prog, ok := c.programs[p.Value]
+	if !ok || int(index) >= len(prog.attribs) {
+		return "", 0, 0
+	}
+	v := prog.attribs[index]
+	return v.Name, v.Size, v.Type
+}
+
+func (c *Context) GetActiveUniform(p gl.Program, index uint32) (name string, size int, ty gl.Enum) {
+	c.record("GetActiveUniform", p, index)
+	prog, ok := c.programs[p.Value]
+	if !ok || int(index) >= len(prog.uniforms) {
+		return "", 0, 0
+	}
+	v := prog.uniforms[index]
+	return v.Name, v.Size, v.Type
+}
+
+var glslTypes = map[string]gl.Enum{
+	"float":       gl.FLOAT,
+	"vec2":        gl.FLOAT_VEC2,
+	"vec3":        gl.FLOAT_VEC3,
+	"vec4":        gl.FLOAT_VEC4,
+	"int":         gl.INT,
+	"ivec2":       gl.INT_VEC2,
+	"ivec3":       gl.INT_VEC3,
+	"ivec4":       gl.INT_VEC4,
+	"bool":        gl.BOOL,
+	"bvec2":       gl.BOOL_VEC2,
+	"bvec3":       gl.BOOL_VEC3,
+	"bvec4":       gl.BOOL_VEC4,
+	"mat2":        gl.FLOAT_MAT2,
+	"mat3":        gl.FLOAT_MAT3,
+	"mat4":        gl.FLOAT_MAT4,
+	"sampler2D":   gl.SAMPLER_2D,
+	"samplerCube": gl.SAMPLER_CUBE,
+}
+
+var (
+	reStruct      = regexp.MustCompile(`struct\s+(\w+)\s*\{([^}]*)\}`)
+	reField       = regexp.MustCompile(`(\w+)\s+(\w+)\s*(?:\[(\d+)\])?\s*;`)
+	reDeclaration = regexp.MustCompile(`(?m)^\s*(attribute|in|uniform)\s+(?:(?:lowp|mediump|highp)\s+)?(\w+)\s+(\w+)\s*(?:\[(\w+)\])?\s*;`)
+	reDefine      = regexp.MustCompile(`(?m)^\s*#define\s+(\w+)\s+(\d+)\s*$`)
+	reBlock       = regexp.MustCompile(`(?m)^\s*(?:layout\s*\([^)]*\)\s*)?uniform\s+(\w+)\s*\{`)
+)
+
+// parseDeclarations finds the attributes and uniforms declared in GLSL source.
+// It understands enough GLSL for tests: one declaration per statement, struct
+// uniforms, and arrays sized by a literal or a #define.
+func parseDeclarations(src string, vertex bool) (attribs, uniforms []Variable) {
+	defines := map[string]int{}
+	for _, m := range reDefine.FindAllStringSubmatch(src, -1) {
+		defines[m[1]], _ = strconv.Atoi(m[2])
+	}
+	size := func(s string) int {
+		if s == "" {
+			return 1
+		}
+		if n, ok := defines[s]; ok {
+			return n
+		}
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+
+	structs := map[string][]Variable{}
+	for _, m := range reStruct.FindAllStringSubmatch(src, -1) {
+		for _, f := range reField.FindAllStringSubmatch(m[2], -1) {
+			structs[m[1]] = append(structs[m[1]], Variable{Name: f[2], Size: size(f[3]), Type: glslTypes[f[1]]})
+		}
+	}
+
+	for _, m := range reDeclaration.FindAllStringSubmatch(src, -1) {
+		qualifier, ty, name, n := m[1], m[2], m[3], size(m[4])
+		if qualifier != "uniform" {
+			if vertex {
+				attribs = append(attribs, Variable{Name: name, Size: n, Type: glslTypes[ty]})
+			}
+			continue
+		}
+
+		fields, isStruct := structs[ty]
+		if !isStruct {
+			if m[4] != "" {
+				name += "[0]"
+			}
+			uniforms = append(uniforms, Variable{Name: name, Size: n, Type: glslTypes[ty]})
+			continue
+		}
+		for i := 0; i < n; i++ {
+			prefix := name
+			if m[4] != "" {
+				prefix = fmt.Sprintf("%s[%d]", name, i)
+			}
+			for _, f := range fields {
+				uniforms = append(uniforms, Variable{Name: prefix + "." + f.Name, Size: f.Size, Type: f.Type})
+			}
+		}
+	}
+	return attribs, uniforms
+}
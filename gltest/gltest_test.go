@@ -0,0 +1,89 @@
+package gltest
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/mobile/gl"
+)
+
+const vertSrc = `
+struct Light {
+	vec3 color;
+	vec3 position;
+};
+
+uniform mat4 model;
+uniform Light lights[2];
+
+attribute vec3 vertCoord;
+attribute vec3 vertNormal;
+
+void main() {}
+`
+
+func TestProgram(t *testing.T) {
+	glctx := NewContext()
+	program := glctx.CreateProgram()
+	vs := glctx.CreateShader(gl.VERTEX_SHADER)
+	glctx.ShaderSource(vs, vertSrc)
+	glctx.CompileShader(vs)
+	glctx.AttachShader(program, vs)
+	glctx.LinkProgram(program)
+
+	if glctx.GetProgrami(program, gl.LINK_STATUS) != 1 {
+		t.Fatal("program failed to link")
+	}
+	if got, want := glctx.GetProgrami(program, gl.ACTIVE_ATTRIBUTES), 2; got != want {
+		t.Errorf("got %d attributes; want %d", got, want)
+	}
+	if got := glctx.GetAttribLocation(program, "vertNormal"); got.Value != 1 {
+		t.Errorf("got %v for vertNormal", got)
+	}
+	if got := glctx.GetUniformLocation(program, "lights[1].position"); got.Value < 0 {
+		t.Errorf("got %v for lights[1].position", got)
+	}
+	if got := glctx.GetUniformLocation(program, "normalMat"); got.Value != -1 {
+		t.Errorf("got %v for undeclared uniform", got)
+	}
+
+	name, size, ty := glctx.GetActiveUniform(program, 0)
+	if name != "model" || size != 1 || ty != gl.FLOAT_MAT4 {
+		t.Errorf("got %q %d %v for first uniform", name, size, ty)
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	glctx := NewContext()
+	glctx.CompileError = func(src string) string { return "0:1: syntax error" }
+
+	s := glctx.CreateShader(gl.FRAGMENT_SHADER)
+	glctx.ShaderSource(s, "oops")
+	glctx.CompileShader(s)
+	if glctx.GetShaderi(s, gl.COMPILE_STATUS) != 0 {
+		t.Error("compile succeeded")
+	}
+	if got := glctx.GetShaderInfoLog(s); got != "0:1: syntax error" {
+		t.Errorf("got log %q", got)
+	}
+}
+
+func TestBuffers(t *testing.T) {
+	glctx := NewContext()
+	b := glctx.CreateBuffer()
+	glctx.BindBuffer(gl.ARRAY_BUFFER, b)
+	glctx.BufferInit(gl.ARRAY_BUFFER, 4, gl.DYNAMIC_DRAW)
+	glctx.BufferSubData(gl.ARRAY_BUFFER, 2, []byte{1, 2, 3})
+
+	if got, want := glctx.BufferBytes(b), []byte{0, 0, 1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	want := []string{"CreateBuffer", "BindBuffer", "BufferInit", "BufferSubData"}
+	if got := glctx.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got := glctx.Filter("BufferSubData")[0].String(); got != "BufferSubData(34962, 2, <3 bytes>)" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,185 @@
+package gameblocks
+
+import (
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/shazow/go-gameblocks/loader"
+	"golang.org/x/mobile/gl"
+)
+
+// InstancedContext is a gl.Context which can draw instances, as on OpenGL ES
+// 3. golang.org/x/mobile/gl does not expose these calls, so platforms wrap
+// their context to provide them. The desktop backend does on OpenGL ES 3; the
+// x/mobile backend cannot, as x/mobile/app keeps its GL thread to itself, so
+// there InstancedNode draws in a loop.
+type InstancedContext interface {
+	gl.Context
+	VertexAttribDivisor(index gl.Attrib, divisor int)
+	DrawArraysInstanced(mode gl.Enum, first, count, instances int)
+	DrawElementsInstanced(mode gl.Enum, count int, ty gl.Enum, offset, instances int)
+}
+
+// Instance is one copy of the shape drawn by an InstancedNode.
+type Instance struct {
+	Transform mgl.Mat4 // Relative to the InstancedNode
+	Color     mgl.Vec4
+}
+
+// instanceDim is the number of floats per Instance in the instance buffer.
+const instanceDim = 16 + colorDim
+
+// NewInstancedNode returns an InstancedNode which draws copies of shape using
+// shader.
+func NewInstancedNode(shape *StaticShape, shader loader.Shader) *InstancedNode {
+	return &InstancedNode{
+		Node: Node{
+			Shape:  shape,
+			shader: shader,
+		},
+		shape:  shape,
+		buffer: shape.glctx.CreateBuffer(),
+	}
+}
+
+// InstancedNode draws many copies of one shape, each with its own transform
+// and color, in a single draw call where the context supports it. Shaders
+// read the instance through attributes:
+//
+//	attribute mat4 instanceModel;
+//	attribute vec4 instanceColor;
+//
+// while the node's own world transform is in the model uniform as usual, so
+// positions are projection * view * model * instanceModel * vertCoord. On
+// contexts without instancing, the attributes are set to constant values for
+// each instance in turn, so the same shader works for both paths.
+type InstancedNode struct {
+	Node
+	shape     *StaticShape
+	instances []Instance
+
+	buffer gl.Buffer
+	dirty  bool
+	enc    Encoder
+}
+
+// Instances returns the instances being drawn.
+func (node *InstancedNode) Instances() []Instance {
+	return node.instances
+}
+
+// SetInstances replaces the instances to draw. The slice is kept, so call
+// SetInstances again after modifying it for the change to be uploaded.
+func (node *InstancedNode) SetInstances(instances []Instance) {
+	node.instances = instances
+	node.dirty = true
+}
+
+func (node *InstancedNode) Draw(ctx DrawContext) {
+	if len(node.instances) == 0 {
+		return
+	}
+	glctx, shader := ctx.GL, ctx.Shader
+
+	view := ctx.Camera.View()
+	model := node.Transform(ctx.Transform)
+	normal := model.Mul4(view).Inv().Transpose()
+	shader.SetMat4("model", model)
+	shader.SetMat4("normalMatrix", normal)
+
+	if ictx, ok := glctx.(InstancedContext); ok {
+		node.drawInstanced(ictx, shader)
+		return
+	}
+
+	columns := instanceColumns(shader)
+	color, hasColor := shader.LookupAttrib("instanceColor")
+	for _, instance := range node.instances {
+		for i, loc := range columns {
+			glctx.VertexAttrib4fv(loc, instance.Transform[i*4:i*4+4])
+		}
+		if hasColor {
+			glctx.VertexAttrib4fv(color, instance.Color[:])
+		}
+		node.shape.Draw(ctx)
+	}
+}
+
+func (node *InstancedNode) drawInstanced(glctx InstancedContext, shader loader.Shader) {
+	shape := node.shape
+	if node.dirty {
+		node.enc.Reset(len(node.instances) * instanceDim * vecSize)
+		for i := range node.instances {
+			node.enc.Float32s(node.instances[i].Transform[:])
+			node.enc.Float32s(node.instances[i].Color[:])
+		}
+		glctx.BindBuffer(gl.ARRAY_BUFFER, node.buffer)
+		glctx.BufferData(gl.ARRAY_BUFFER, node.enc.Bytes(), gl.DYNAMIC_DRAW)
+		node.dirty = false
+	}
+
+	glctx.BindBuffer(gl.ARRAY_BUFFER, shape.VBO)
+	shape.layout.Enable(glctx, shader)
+
+	// A mat4 attribute takes four consecutive locations, one per column.
+	stride := instanceDim * vecSize
+	glctx.BindBuffer(gl.ARRAY_BUFFER, node.buffer)
+	attribs := instanceColumns(shader)
+	for i, loc := range attribs {
+		glctx.EnableVertexAttribArray(loc)
+		glctx.VertexAttribPointer(loc, 4, gl.FLOAT, false, stride, i*4*vecSize)
+	}
+	if color, ok := shader.LookupAttrib("instanceColor"); ok {
+		glctx.EnableVertexAttribArray(color)
+		glctx.VertexAttribPointer(color, colorDim, gl.FLOAT, false, stride, 16*vecSize)
+		attribs = append(attribs, color)
+	}
+	for _, loc := range attribs {
+		glctx.VertexAttribDivisor(loc, 1)
+	}
+
+	shape.bindTexture(shader)
+	if shape.numIndices > 0 {
+		glctx.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, shape.IBO)
+		glctx.DrawElementsInstanced(gl.TRIANGLES, shape.numIndices, shape.indexType, 0, len(node.instances))
+	} else {
+		glctx.DrawArraysInstanced(gl.TRIANGLES, 0, shape.Len(), len(node.instances))
+	}
+
+	for _, loc := range attribs {
+		glctx.VertexAttribDivisor(loc, 0)
+		glctx.DisableVertexAttribArray(loc)
+	}
+	shape.layout.Disable(glctx, shader)
+}
+
+// instanceColumns returns the locations of the instanceModel columns, or none
+// if the shader does not use it.
+func instanceColumns(shader loader.Shader) []gl.Attrib {
+	loc, ok := shader.LookupAttrib("instanceModel")
+	if !ok {
+		return nil
+	}
+	columns := make([]gl.Attrib, 4)
+	for i := range columns {
+		columns[i] = gl.Attrib{Value: loc.Value + uint(i)}
+	}
+	return columns
+}
+
+// Bounds returns the bounding box of all instances in the node's local space.
+func (node *InstancedNode) Bounds() (AABB, bool) {
+	if len(node.instances) == 0 {
+		return AABB{}, false
+	}
+	local := node.shape.Bounds()
+	bounds := local.Transform(node.instances[0].Transform)
+	for _, instance := range node.instances[1:] {
+		bounds = bounds.Union(local.Transform(instance.Transform))
+	}
+	return bounds, true
+}
+
+// Close releases the instance buffer and the shape.
+func (node *InstancedNode) Close() error {
+	node.shape.glctx.DeleteBuffer(node.buffer)
+	return node.shape.Close()
+}
@@ -0,0 +1,83 @@
+package gameblocks
+
+import (
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/shazow/go-gameblocks/camera"
+	"github.com/shazow/go-gameblocks/gltest"
+	"github.com/shazow/go-gameblocks/loader"
+	"golang.org/x/mobile/gl"
+)
+
+const testInstancedShader = `
+uniform mat4 model;
+uniform mat4 view;
+uniform mat4 projection;
+uniform mat4 normalMatrix;
+
+attribute vec3 vertCoord;
+attribute mat4 instanceModel;
+attribute vec4 instanceColor;
+
+void main() {}
+`
+
+func newInstancedNode(t *testing.T, glctx gl.Context) (*InstancedNode, loader.Shader) {
+	shader, err := loader.NewShaderSource(glctx, testInstancedShader, "void main() {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shape, err := NewMeshShape(glctx, Mesh{Positions: []float32{0, 0, 0, 1, 0, 0, 0, 1, 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := NewInstancedNode(shape, shader)
+	node.SetInstances([]Instance{
+		{Transform: mgl.Translate3D(1, 0, 0), Color: mgl.Vec4{1, 0, 0, 1}},
+		{Transform: mgl.Translate3D(2, 0, 0), Color: mgl.Vec4{0, 1, 0, 1}},
+		{Transform: mgl.Translate3D(3, 0, 0), Color: mgl.Vec4{0, 0, 1, 1}},
+	})
+	return node, shader
+}
+
+func TestInstancedNodeDraw(t *testing.T) {
+	glctx := gltest.NewContext3()
+	node, shader := newInstancedNode(t, glctx)
+
+	glctx.Reset()
+	node.Draw(DrawContext{GL: glctx, Camera: camera.FixedCamera{}, Shader: shader})
+
+	draws := glctx.Filter("DrawArrays", "DrawArraysInstanced")
+	if len(draws) != 1 || draws[0].Name != "DrawArraysInstanced" || draws[0].Args[3] != 3 {
+		t.Fatalf("got draws %v; want one instanced draw of 3", draws)
+	}
+	if got, want := len(glctx.BufferBytes(node.buffer)), 3*instanceDim*vecSize; got != want {
+		t.Errorf("got %d bytes of instances; want %d", got, want)
+	}
+	// Four columns of instanceModel and instanceColor, set and reset.
+	if got := len(glctx.Filter("VertexAttribDivisor")); got != 2*5 {
+		t.Errorf("got %d divisor calls; want %d", got, 2*5)
+	}
+}
+
+func TestInstancedNodeDrawFallback(t *testing.T) {
+	glctx := gltest.NewContext()
+	node, shader := newInstancedNode(t, glctx)
+
+	glctx.Reset()
+	node.Draw(DrawContext{GL: glctx, Camera: camera.FixedCamera{}, Shader: shader})
+
+	if got := len(glctx.Filter("DrawArrays")); got != 3 {
+		t.Errorf("got %d draws; want 3", got)
+	}
+	colors := 0
+	for _, call := range glctx.Filter("VertexAttrib4fv") {
+		if call.Args[0] == shader.Attrib("instanceColor") {
+			colors++
+		}
+	}
+	if colors != 3 {
+		t.Errorf("got %d instance colors set; want 3", colors)
+	}
+}
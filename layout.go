@@ -0,0 +1,126 @@
+package gameblocks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shazow/go-gameblocks/loader"
+	"golang.org/x/mobile/gl"
+)
+
+// VertexAttrib is a named shader attribute within an interleaved vertex.
+type VertexAttrib struct {
+	Name       string
+	Type       gl.Enum // FLOAT, BYTE, UNSIGNED_SHORT, ...
+	Dim        int
+	Normalized bool
+	Offset     int // Bytes from the start of the vertex
+}
+
+// VertexLayout describes how the attributes of a vertex are interleaved in a
+// buffer, so they can be bound to a shader in one call.
+type VertexLayout struct {
+	Attribs []VertexAttrib
+	Stride  int // Bytes per vertex
+}
+
+// NewVertexLayout returns a layout of attribs packed in order. Offsets are
+// computed, so any set on attribs are ignored.
+func NewVertexLayout(attribs ...VertexAttrib) VertexLayout {
+	var layout VertexLayout
+	for _, a := range attribs {
+		layout.Add(a.Name, a.Type, a.Dim, a.Normalized)
+	}
+	return layout
+}
+
+// Add appends an attribute after the existing ones. Attributes are aligned to
+// four bytes, as GL implementations expect, so encode vertices for the layout
+// with Encoder.EncodeLayout.
+func (layout *VertexLayout) Add(name string, typ gl.Enum, dim int, normalized bool) {
+	layout.Attribs = append(layout.Attribs, VertexAttrib{
+		Name:       name,
+		Type:       typ,
+		Dim:        dim,
+		Normalized: normalized,
+		Offset:     layout.Stride,
+	})
+	size := typeSize(typ) * dim
+	layout.Stride += (size + 3) &^ 3
+}
+
+// AddSlice appends an attribute with the type and dimension of s.
+func (layout *VertexLayout) AddSlice(name string, s DimSlicer) {
+	layout.Add(name, s.Type(), s.Dim(), s.Normalized())
+}
+
+// Enable points each attribute the shader declares at the buffer bound to
+// ARRAY_BUFFER. Attributes the shader lacks are skipped.
+func (layout VertexLayout) Enable(glctx gl.Context, shader loader.Shader) {
+	for _, a := range layout.Attribs {
+		loc, ok := shader.LookupAttrib(a.Name)
+		if !ok {
+			continue
+		}
+		glctx.EnableVertexAttribArray(loc)
+		glctx.VertexAttribPointer(loc, a.Dim, a.Type, a.Normalized, layout.Stride, a.Offset)
+	}
+}
+
+// Disable reverses Enable.
+func (layout VertexLayout) Disable(glctx gl.Context, shader loader.Shader) {
+	for _, a := range layout.Attribs {
+		if loc, ok := shader.LookupAttrib(a.Name); ok {
+			glctx.DisableVertexAttribArray(loc)
+		}
+	}
+}
+
+// Check returns an error listing the attributes which the shader does not
+// declare, or which were optimized out for being unused.
+func (layout VertexLayout) Check(shader loader.Shader) error {
+	var missing []string
+	for _, a := range layout.Attribs {
+		if _, ok := shader.LookupAttrib(a.Name); !ok {
+			missing = append(missing, a.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("layout: shader has no attribute %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Equal reports whether both layouts have the same attributes and stride.
+func (layout VertexLayout) Equal(other VertexLayout) bool {
+	if layout.Stride != other.Stride || len(layout.Attribs) != len(other.Attribs) {
+		return false
+	}
+	for i, a := range layout.Attribs {
+		if a != other.Attribs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (layout VertexLayout) String() string {
+	names := make([]string, len(layout.Attribs))
+	for i, a := range layout.Attribs {
+		names[i] = fmt.Sprintf("%s@%d", a.Name, a.Offset)
+	}
+	return fmt.Sprintf("<VertexLayout %s; stride: %d>", strings.Join(names, " "), layout.Stride)
+}
+
+// typeSize returns the size in bytes of a GL component type.
+func typeSize(typ gl.Enum) int {
+	switch typ {
+	case gl.BYTE, gl.UNSIGNED_BYTE:
+		return 1
+	case gl.SHORT, gl.UNSIGNED_SHORT:
+		return 2
+	case gl.INT, gl.UNSIGNED_INT, gl.FLOAT:
+		return 4
+	}
+	panic(fmt.Sprintf("gameblocks: unsupported attribute type %v", typ))
+}
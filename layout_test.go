@@ -0,0 +1,74 @@
+package gameblocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shazow/go-gameblocks/gltest"
+	"golang.org/x/mobile/gl"
+)
+
+func TestVertexLayout(t *testing.T) {
+	layout := NewVertexLayout(
+		VertexAttrib{Name: "vertCoord", Type: gl.FLOAT, Dim: 3},
+		VertexAttrib{Name: "vertNormal", Type: gl.BYTE, Dim: 3, Normalized: true},
+		VertexAttrib{Name: "vertColor", Type: gl.UNSIGNED_BYTE, Dim: 4, Normalized: true},
+	)
+	if got, want := layout.Stride, 12+4+4; got != want {
+		t.Errorf("got stride %d; want %d", got, want)
+	}
+	if got, want := layout.Attribs[2].Offset, 16; got != want {
+		t.Errorf("got color offset %d; want %d", got, want)
+	}
+
+	glctx := gltest.NewContext()
+	shader := newTestShader(t, glctx)
+	if err := layout.Check(shader); err == nil {
+		t.Error("expected error for undeclared vertColor")
+	}
+
+	glctx.Reset()
+	layout.Enable(glctx, shader)
+	pointers := glctx.Filter("VertexAttribPointer")
+	if len(pointers) != 2 {
+		t.Fatalf("got %d attribute pointers; want 2", len(pointers))
+	}
+	normal := pointers[1].Args
+	if normal[0] != shader.Attrib("vertNormal") || normal[2] != gl.Enum(gl.BYTE) || normal[3] != true || normal[5] != 12 {
+		t.Errorf("got normal pointer %v", normal)
+	}
+
+	glctx.Reset()
+	layout.Disable(glctx, shader)
+	if got := len(glctx.Filter("DisableVertexAttribArray")); got != 2 {
+		t.Errorf("got %d attributes disabled; want 2", got)
+	}
+}
+
+func TestEncodeLayout(t *testing.T) {
+	coords := NewAttribSlice(3, []float32{1, 2, 3, 4, 5, 6})
+	normals := NewNormalizedSlice(3, []int8{-1, 0, 1, 2, 3, 4})
+	colors := NewNormalizedSlice(4, []uint8{10, 11, 12, 13, 20, 21, 22, 23})
+	var layout VertexLayout
+	layout.AddSlice("vertCoord", coords)
+	layout.AddSlice("vertNormal", normals)
+	layout.AddSlice("vertColor", colors)
+
+	var enc Encoder
+	data := enc.EncodeLayout(layout, 0, 2, coords, normals, colors)
+	if got, want := len(data), 2*layout.Stride; got != want {
+		t.Fatalf("got %d bytes; want %d", got, want)
+	}
+	objects := []DimSlicer{coords, normals, colors}
+	for v := 0; v < 2; v++ {
+		row := data[v*layout.Stride:]
+		for i, a := range layout.Attribs {
+			var want Encoder
+			objects[i].Encode(&want, v*a.Dim, (v+1)*a.Dim)
+			got := row[a.Offset : a.Offset+want.Len()]
+			if !bytes.Equal(got, want.Bytes()) {
+				t.Errorf("vertex %d %s at offset %d: got %v; want %v", v, a.Name, a.Offset, got, want.Bytes())
+			}
+		}
+	}
+}
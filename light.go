@@ -0,0 +1,167 @@
+package gameblocks
+
+import (
+	"fmt"
+	"math"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/shazow/go-gameblocks/loader"
+)
+
+// DefaultMaxLights is the number of lights uploaded when
+// EngineOptions.MaxLights is not set.
+const DefaultMaxLights = 8
+
+type LightType int
+
+const (
+	DirectionalLight LightType = iota
+	PointLight
+	SpotLight
+)
+
+// Light is a light source added to a Scene. Lights are uploaded to every
+// shader once per frame, as an array of structs:
+//
+//	struct Light {
+//		int type;          // 0: directional, 1: point, 2: spot
+//		vec3 color;
+//		vec3 position;     // World space; point and spot
+//		vec3 direction;    // World space, normalized; directional and spot
+//		vec3 attenuation;  // Constant, linear, quadratic; point and spot
+//		float cutoff;      // Cosine of the inner cone angle; spot
+//		float outerCutoff; // Cosine of the outer cone angle; spot
+//	};
+//	uniform Light lights[MaxLights];
+//	uniform int numLights;
+//
+// Only the first numLights elements are set. Shaders may omit any field they
+// don't use.
+type Light struct {
+	kind        LightType
+	color       mgl.Vec3
+	position    mgl.Vec3
+	direction   mgl.Vec3
+	attenuation mgl.Vec3
+	cutoff      float32
+	outerCutoff float32
+}
+
+// NewDirectionalLight returns a light infinitely far away, shining along
+// direction, like the sun.
+func NewDirectionalLight(color mgl.Vec3, direction mgl.Vec3) *Light {
+	return &Light{
+		kind:      DirectionalLight,
+		color:     color,
+		direction: direction.Normalize(),
+	}
+}
+
+// NewPointLight returns a light shining in every direction from position.
+func NewPointLight(color mgl.Vec3, position mgl.Vec3) *Light {
+	return &Light{
+		kind:        PointLight,
+		color:       color,
+		position:    position,
+		attenuation: mgl.Vec3{1, 0.09, 0.032},
+	}
+}
+
+// NewSpotLight returns a light shining a cone from position along direction.
+// Angle is the half-angle of the cone in radians; the edge is softened over
+// an extra tenth of it.
+func NewSpotLight(color mgl.Vec3, position mgl.Vec3, direction mgl.Vec3, angle float32) *Light {
+	return &Light{
+		kind:        SpotLight,
+		color:       color,
+		position:    position,
+		direction:   direction.Normalize(),
+		attenuation: mgl.Vec3{1, 0.09, 0.032},
+		cutoff:      float32(math.Cos(float64(angle))),
+		outerCutoff: float32(math.Cos(float64(angle * 1.1))),
+	}
+}
+
+func (light *Light) Type() LightType {
+	return light.kind
+}
+
+func (light *Light) Color() mgl.Vec3 {
+	return light.color
+}
+
+func (light *Light) SetColor(color mgl.Vec3) {
+	light.color = color
+}
+
+func (light *Light) Position() mgl.Vec3 {
+	return light.position
+}
+
+func (light *Light) MoveTo(position mgl.Vec3) {
+	light.position = position
+}
+
+func (light *Light) Direction() mgl.Vec3 {
+	return light.direction
+}
+
+// PointAt sets the direction of directional and spot lights.
+func (light *Light) PointAt(direction mgl.Vec3) {
+	light.direction = direction.Normalize()
+}
+
+// SetAttenuation sets the constant, linear and quadratic falloff of point and
+// spot lights over distance.
+func (light *Light) SetAttenuation(constant, linear, quadratic float32) {
+	light.attenuation = mgl.Vec3{constant, linear, quadratic}
+}
+
+func (light *Light) String() string {
+	return fmt.Sprintf("<Light type=%d color=%v position=%v direction=%v>", light.kind, light.color, light.position, light.direction)
+}
+
+// lightUniforms are the uniform names of an element of the lights array.
+type lightUniforms struct {
+	kind, color, position, direction, attenuation, cutoff, outerCutoff string
+}
+
+// lightNames holds the uniform names of each lights element used so far, so
+// they are not built again every frame.
+var lightNames []lightUniforms
+
+// lightUniformNames returns the uniform names of the first n lights.
+func lightUniformNames(n int) []lightUniforms {
+	for i := len(lightNames); i < n; i++ {
+		prefix := fmt.Sprintf("lights[%d].", i)
+		lightNames = append(lightNames, lightUniforms{
+			kind:        prefix + "type",
+			color:       prefix + "color",
+			position:    prefix + "position",
+			direction:   prefix + "direction",
+			attenuation: prefix + "attenuation",
+			cutoff:      prefix + "cutoff",
+			outerCutoff: prefix + "outerCutoff",
+		})
+	}
+	return lightNames[:n]
+}
+
+// bindLights uploads up to max lights to the shader's lights uniform array.
+func bindLights(shader loader.Shader, lights []*Light, max int) {
+	if len(lights) > max {
+		lights = lights[:max]
+	}
+	shader.SetInt("numLights", len(lights))
+	names := lightUniformNames(len(lights))
+	for i, light := range lights {
+		name := &names[i]
+		shader.SetInt(name.kind, int(light.kind))
+		shader.SetVec3(name.color, light.color)
+		shader.SetVec3(name.position, light.position)
+		shader.SetVec3(name.direction, light.direction)
+		shader.SetVec3(name.attenuation, light.attenuation)
+		shader.SetFloat(name.cutoff, light.cutoff)
+		shader.SetFloat(name.outerCutoff, light.outerCutoff)
+	}
+}
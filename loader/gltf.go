@@ -0,0 +1,982 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/ioutil"
+	"math"
+	"path"
+	"strings"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"golang.org/x/mobile/asset"
+)
+
+// GLTF is a glTF 2.0 model, decoded from a binary .glb or a .gltf file. Index
+// fields refer to the slices of this struct, and are -1 when absent.
+type GLTF struct {
+	// Roots are the top-level nodes of the default scene.
+	Roots      []int
+	Nodes      []*GLTFNode
+	Meshes     []*GLTFMesh
+	Materials  []*PBRMaterial
+	Textures   []*GLTFTexture
+	Images     []image.Image
+	Skins      []*GLTFSkin
+	Animations []*GLTFAnimation
+}
+
+// GLTFNode is an element of the node hierarchy. Its local transform is
+// Translation * Rotation * Scale.
+type GLTFNode struct {
+	Name     string
+	Children []int
+	Mesh     int
+	Skin     int
+
+	Translation mgl.Vec3
+	Rotation    mgl.Quat
+	Scale       mgl.Vec3
+}
+
+// Transform returns the local transform of the node.
+func (node *GLTFNode) Transform() mgl.Mat4 {
+	return TRS(node.Translation, node.Rotation, node.Scale)
+}
+
+// TRS composes a transform from translation, rotation and scale.
+func TRS(t mgl.Vec3, r mgl.Quat, s mgl.Vec3) mgl.Mat4 {
+	return mgl.Translate3D(t[0], t[1], t[2]).Mul4(r.Mat4()).Mul4(mgl.Scale3D(s[0], s[1], s[2]))
+}
+
+type GLTFMesh struct {
+	Name       string
+	Primitives []*GLTFPrimitive
+}
+
+// GLTFPrimitive is a triangle list with vertex data in the same layout as an
+// OBJGroup. Colors are Vec4. Joints and Weights hold four influences per
+// vertex for skinning.
+type GLTFPrimitive struct {
+	Positions []float32
+	Normals   []float32
+	TexCoords []float32
+	Colors    []float32
+	Joints    []uint16
+	Weights   []float32
+	Indices   []uint32
+	Material  int
+}
+
+// PBRMaterial holds the metallic-roughness parameters of a glTF material.
+// Texture fields index GLTF.Textures.
+type PBRMaterial struct {
+	Name             string
+	BaseColor        mgl.Vec4
+	BaseColorTexture int
+	Metallic         float32
+	Roughness        float32
+	// MetallicRoughnessTexture stores metalness in blue, roughness in green.
+	MetallicRoughnessTexture int
+	NormalTexture            int
+	OcclusionTexture         int
+	EmissiveTexture          int
+	Emissive                 mgl.Vec3
+	AlphaMode                string // OPAQUE, MASK or BLEND
+	AlphaCutoff              float32
+	DoubleSided              bool
+}
+
+// GLTFTexture pairs an image with sampling parameters. Filter and wrap values
+// are GL enums, zero if unspecified.
+type GLTFTexture struct {
+	Image     int
+	MagFilter int
+	MinFilter int
+	WrapS     int
+	WrapT     int
+}
+
+// GLTFSkin binds mesh vertices to a hierarchy of joint nodes.
+type GLTFSkin struct {
+	Name                string
+	Joints              []int
+	InverseBindMatrices []mgl.Mat4
+	Skeleton            int
+}
+
+type GLTFAnimation struct {
+	Name     string
+	Channels []*GLTFChannel
+}
+
+// GLTFChannel animates one property of a node. Path is translation, rotation,
+// scale or weights. Values holds one element per keyframe (Vec3 or Quat), or
+// three per keyframe (in-tangent, value, out-tangent) for CUBICSPLINE.
+type GLTFChannel struct {
+	Node          int
+	Path          string
+	Interpolation string // LINEAR, STEP or CUBICSPLINE
+	Times         []float32
+	Values        []float32
+}
+
+// Duration returns the time of the last keyframe, in seconds.
+func (anim *GLTFAnimation) Duration() float32 {
+	var d float32
+	for _, c := range anim.Channels {
+		if n := len(c.Times); n > 0 && c.Times[n-1] > d {
+			d = c.Times[n-1]
+		}
+	}
+	return d
+}
+
+// Sample returns the channel value at time t in seconds, clamped to the
+// keyframe range. Rotations are returned as x, y, z, w.
+func (c *GLTFChannel) Sample(t float32) []float32 {
+	n := len(c.Times)
+	if n == 0 {
+		return nil
+	}
+	dim := len(c.Values) / n
+	if c.Interpolation == "CUBICSPLINE" {
+		dim /= 3
+	}
+	value := func(i int) []float32 {
+		if c.Interpolation == "CUBICSPLINE" {
+			return c.Values[(3*i+1)*dim : (3*i+2)*dim]
+		}
+		return c.Values[i*dim : (i+1)*dim]
+	}
+
+	if t <= c.Times[0] {
+		return value(0)
+	}
+	if t >= c.Times[n-1] {
+		return value(n - 1)
+	}
+	i := 0
+	for i < n-2 && t >= c.Times[i+1] {
+		i++
+	}
+	dt := c.Times[i+1] - c.Times[i]
+	s := (t - c.Times[i]) / dt
+
+	a, b := value(i), value(i+1)
+	r := make([]float32, dim)
+	switch c.Interpolation {
+	case "STEP":
+		copy(r, a)
+		return r
+	case "CUBICSPLINE":
+		outA := c.Values[(3*i+2)*dim : (3*i+3)*dim]
+		inB := c.Values[(3*(i+1))*dim : (3*(i+1)+1)*dim]
+		s2, s3 := s*s, s*s*s
+		for j := range r {
+			r[j] = (2*s3-3*s2+1)*a[j] + (s3-2*s2+s)*dt*outA[j] + (-2*s3+3*s2)*b[j] + (s3-s2)*dt*inB[j]
+		}
+	default:
+		if c.Path == "rotation" {
+			qa := mgl.Quat{W: a[3], V: mgl.Vec3{a[0], a[1], a[2]}}
+			qb := mgl.Quat{W: b[3], V: mgl.Vec3{b[0], b[1], b[2]}}
+			q := mgl.QuatSlerp(qa, qb, s)
+			return []float32{q.V[0], q.V[1], q.V[2], q.W}
+		}
+		for j := range r {
+			r[j] = a[j] + (b[j]-a[j])*s
+		}
+	}
+	if c.Path == "rotation" {
+		q := mgl.Quat{W: r[3], V: mgl.Vec3{r[0], r[1], r[2]}}.Normalize()
+		return []float32{q.V[0], q.V[1], q.V[2], q.W}
+	}
+	return r
+}
+
+// LoadGLTF reads a .glb or .gltf model from the asset repository. External
+// buffers and images are resolved relative to it.
+func LoadGLTF(name string) (*GLTF, error) {
+	f, err := asset.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dir := path.Dir(name)
+	return ParseGLTF(f, func(uri string) (io.ReadCloser, error) {
+		return asset.Open(path.Join(dir, uri))
+	})
+}
+
+const glbMagic = 0x46546c67 // "glTF"
+
+// ParseGLTF parses a binary .glb or a JSON .gltf model. External URIs are
+// opened with openURI, which may be nil if the model is self-contained.
+func ParseGLTF(r io.Reader, openURI func(uri string) (io.ReadCloser, error)) (*GLTF, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc gltfDoc
+	var bin []byte
+	if len(data) >= 12 && binary.LittleEndian.Uint32(data) == glbMagic {
+		jsonChunk, binChunk, err := parseGLB(data)
+		if err != nil {
+			return nil, err
+		}
+		data, bin = jsonChunk, binChunk
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("gltf: %v", err)
+	}
+	if !strings.HasPrefix(doc.Asset.Version, "2.") {
+		return nil, fmt.Errorf("gltf: unsupported version %q", doc.Asset.Version)
+	}
+
+	d := &gltfDecoder{doc: &doc, bin: bin, openURI: openURI}
+	return d.decode()
+}
+
+// parseGLB splits a binary glTF container into its JSON and BIN chunks.
+func parseGLB(data []byte) (jsonChunk, binChunk []byte, err error) {
+	if version := binary.LittleEndian.Uint32(data[4:]); version != 2 {
+		return nil, nil, fmt.Errorf("gltf: unsupported GLB version %d", version)
+	}
+	length := int(binary.LittleEndian.Uint32(data[8:]))
+	if length > len(data) {
+		return nil, nil, fmt.Errorf("gltf: GLB truncated, %d of %d bytes", len(data), length)
+	}
+	for offset := 12; offset+8 <= length; {
+		size := int(binary.LittleEndian.Uint32(data[offset:]))
+		kind := binary.LittleEndian.Uint32(data[offset+4:])
+		start := offset + 8
+		if start+size > length {
+			return nil, nil, fmt.Errorf("gltf: GLB chunk overruns file")
+		}
+		switch kind {
+		case 0x4e4f534a: // JSON
+			jsonChunk = data[start : start+size]
+		case 0x004e4942: // BIN
+			binChunk = data[start : start+size]
+		}
+		offset = start + size
+	}
+	if jsonChunk == nil {
+		return nil, nil, fmt.Errorf("gltf: GLB has no JSON chunk")
+	}
+	return jsonChunk, binChunk, nil
+}
+
+// The JSON schema, limited to what is decoded. Optional indices are pointers.
+type gltfDoc struct {
+	Asset struct {
+		Version string `json:"version"`
+	} `json:"asset"`
+	Scene  *int `json:"scene"`
+	Scenes []struct {
+		Nodes []int `json:"nodes"`
+	} `json:"scenes"`
+	Nodes []struct {
+		Name        string    `json:"name"`
+		Children    []int     `json:"children"`
+		Mesh        *int      `json:"mesh"`
+		Skin        *int      `json:"skin"`
+		Matrix      []float32 `json:"matrix"`
+		Translation []float32 `json:"translation"`
+		Rotation    []float32 `json:"rotation"`
+		Scale       []float32 `json:"scale"`
+	} `json:"nodes"`
+	Meshes []struct {
+		Name       string `json:"name"`
+		Primitives []struct {
+			Attributes map[string]int `json:"attributes"`
+			Indices    *int           `json:"indices"`
+			Material   *int           `json:"material"`
+			Mode       *int           `json:"mode"`
+		} `json:"primitives"`
+	} `json:"meshes"`
+	Accessors []struct {
+		BufferView    *int   `json:"bufferView"`
+		ByteOffset    int    `json:"byteOffset"`
+		ComponentType int    `json:"componentType"`
+		Normalized    bool   `json:"normalized"`
+		Count         int    `json:"count"`
+		Type          string `json:"type"`
+		Sparse        *struct {
+		} `json:"sparse"`
+	} `json:"accessors"`
+	BufferViews []struct {
+		Buffer     int `json:"buffer"`
+		ByteOffset int `json:"byteOffset"`
+		ByteLength int `json:"byteLength"`
+		ByteStride int `json:"byteStride"`
+	} `json:"bufferViews"`
+	Buffers []struct {
+		ByteLength int    `json:"byteLength"`
+		URI        string `json:"uri"`
+	} `json:"buffers"`
+	Materials []struct {
+		Name                 string `json:"name"`
+		PBRMetallicRoughness *struct {
+			BaseColorFactor          []float32   `json:"baseColorFactor"`
+			BaseColorTexture         *gltfTexRef `json:"baseColorTexture"`
+			MetallicFactor           *float32    `json:"metallicFactor"`
+			RoughnessFactor          *float32    `json:"roughnessFactor"`
+			MetallicRoughnessTexture *gltfTexRef `json:"metallicRoughnessTexture"`
+		} `json:"pbrMetallicRoughness"`
+		NormalTexture    *gltfTexRef `json:"normalTexture"`
+		OcclusionTexture *gltfTexRef `json:"occlusionTexture"`
+		EmissiveTexture  *gltfTexRef `json:"emissiveTexture"`
+		EmissiveFactor   []float32   `json:"emissiveFactor"`
+		AlphaMode        string      `json:"alphaMode"`
+		AlphaCutoff      *float32    `json:"alphaCutoff"`
+		DoubleSided      bool        `json:"doubleSided"`
+	} `json:"materials"`
+	Textures []struct {
+		Sampler *int `json:"sampler"`
+		Source  *int `json:"source"`
+	} `json:"textures"`
+	Images []struct {
+		URI        string `json:"uri"`
+		MimeType   string `json:"mimeType"`
+		BufferView *int   `json:"bufferView"`
+	} `json:"images"`
+	Samplers []struct {
+		MagFilter int `json:"magFilter"`
+		MinFilter int `json:"minFilter"`
+		WrapS     int `json:"wrapS"`
+		WrapT     int `json:"wrapT"`
+	} `json:"samplers"`
+	Skins []struct {
+		Name                string `json:"name"`
+		InverseBindMatrices *int   `json:"inverseBindMatrices"`
+		Skeleton            *int   `json:"skeleton"`
+		Joints              []int  `json:"joints"`
+	} `json:"skins"`
+	Animations []struct {
+		Name     string `json:"name"`
+		Channels []struct {
+			Sampler int `json:"sampler"`
+			Target  struct {
+				Node *int   `json:"node"`
+				Path string `json:"path"`
+			} `json:"target"`
+		} `json:"channels"`
+		Samplers []struct {
+			Input         int    `json:"input"`
+			Output        int    `json:"output"`
+			Interpolation string `json:"interpolation"`
+		} `json:"samplers"`
+	} `json:"animations"`
+}
+
+type gltfTexRef struct {
+	Index int `json:"index"`
+}
+
+func optIndex(i *int) int {
+	if i == nil {
+		return -1
+	}
+	return *i
+}
+
+func texIndex(ref *gltfTexRef) int {
+	if ref == nil {
+		return -1
+	}
+	return ref.Index
+}
+
+type gltfDecoder struct {
+	doc     *gltfDoc
+	bin     []byte
+	openURI func(string) (io.ReadCloser, error)
+	buffers [][]byte
+}
+
+// ValidateNodes checks that the nodes form trees: each node has at most one
+// parent, there are no cycles, and the roots have no parent.
+func (gltf *GLTF) ValidateNodes() error {
+	parent := make([]int, len(gltf.Nodes))
+	for i := range parent {
+		parent[i] = -1
+	}
+	for i, n := range gltf.Nodes {
+		for _, c := range n.Children {
+			if c < 0 || c >= len(gltf.Nodes) {
+				return fmt.Errorf("gltf: node %d: child %d out of range", i, c)
+			}
+			if parent[c] >= 0 {
+				return fmt.Errorf("gltf: node %d is a child of both node %d and node %d", c, parent[c], i)
+			}
+			parent[c] = i
+		}
+	}
+	root := make([]bool, len(gltf.Nodes))
+	for _, r := range gltf.Roots {
+		if r < 0 || r >= len(gltf.Nodes) {
+			return fmt.Errorf("gltf: scene: node %d out of range", r)
+		}
+		if parent[r] >= 0 {
+			return fmt.Errorf("gltf: scene: node %d is a child of node %d", r, parent[r])
+		}
+		if root[r] {
+			return fmt.Errorf("gltf: scene: node %d listed twice", r)
+		}
+		root[r] = true
+	}
+	// With one parent each, a node is in or below a cycle if following its
+	// parents never ends.
+	for i := range gltf.Nodes {
+		p := i
+		for steps := 0; p >= 0; steps++ {
+			if steps > len(gltf.Nodes) {
+				return fmt.Errorf("gltf: node %d is in a cycle", i)
+			}
+			p = parent[p]
+		}
+	}
+	return nil
+}
+
+func (d *gltfDecoder) decode() (*GLTF, error) {
+	doc := d.doc
+	model := &GLTF{}
+
+	for i, b := range doc.Buffers {
+		data, err := d.loadBuffer(i, b.URI)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < b.ByteLength {
+			return nil, fmt.Errorf("gltf: buffer %d has %d bytes, want %d", i, len(data), b.ByteLength)
+		}
+		d.buffers = append(d.buffers, data)
+	}
+
+	for _, n := range doc.Nodes {
+		node := &GLTFNode{
+			Name:     n.Name,
+			Children: n.Children,
+			Mesh:     optIndex(n.Mesh),
+			Skin:     optIndex(n.Skin),
+			Rotation: mgl.QuatIdent(),
+			Scale:    mgl.Vec3{1, 1, 1},
+		}
+		if len(n.Matrix) == 16 {
+			var m mgl.Mat4
+			copy(m[:], n.Matrix)
+			node.Translation, node.Rotation, node.Scale = decomposeTRS(m)
+		}
+		if len(n.Translation) == 3 {
+			copy(node.Translation[:], n.Translation)
+		}
+		if len(n.Rotation) == 4 {
+			node.Rotation = mgl.Quat{W: n.Rotation[3], V: mgl.Vec3{n.Rotation[0], n.Rotation[1], n.Rotation[2]}}
+		}
+		if len(n.Scale) == 3 {
+			copy(node.Scale[:], n.Scale)
+		}
+		model.Nodes = append(model.Nodes, node)
+	}
+
+	switch {
+	case len(doc.Scenes) > 0:
+		scene := 0
+		if doc.Scene != nil {
+			scene = *doc.Scene
+		}
+		if scene < 0 || scene >= len(doc.Scenes) {
+			return nil, fmt.Errorf("gltf: scene %d out of range", scene)
+		}
+		model.Roots = doc.Scenes[scene].Nodes
+	default:
+		// No scenes: every node without a parent is a root.
+		child := make([]bool, len(model.Nodes))
+		for _, n := range model.Nodes {
+			for _, c := range n.Children {
+				if c >= 0 && c < len(child) {
+					child[c] = true
+				}
+			}
+		}
+		for i := range model.Nodes {
+			if !child[i] {
+				model.Roots = append(model.Roots, i)
+			}
+		}
+	}
+
+	if err := model.ValidateNodes(); err != nil {
+		return nil, err
+	}
+
+	for i, m := range doc.Meshes {
+		mesh := &GLTFMesh{Name: m.Name}
+		for j, p := range m.Primitives {
+			prim, err := d.primitive(p.Attributes, p.Indices, p.Mode)
+			if err != nil {
+				return nil, fmt.Errorf("gltf: mesh %d primitive %d: %v", i, j, err)
+			}
+			prim.Material = optIndex(p.Material)
+			mesh.Primitives = append(mesh.Primitives, prim)
+		}
+		model.Meshes = append(model.Meshes, mesh)
+	}
+
+	for _, m := range doc.Materials {
+		mat := &PBRMaterial{
+			Name:                     m.Name,
+			BaseColor:                mgl.Vec4{1, 1, 1, 1},
+			BaseColorTexture:         -1,
+			Metallic:                 1,
+			Roughness:                1,
+			MetallicRoughnessTexture: -1,
+			NormalTexture:            texIndex(m.NormalTexture),
+			OcclusionTexture:         texIndex(m.OcclusionTexture),
+			EmissiveTexture:          texIndex(m.EmissiveTexture),
+			AlphaMode:                "OPAQUE",
+			AlphaCutoff:              0.5,
+			DoubleSided:              m.DoubleSided,
+		}
+		if pbr := m.PBRMetallicRoughness; pbr != nil {
+			if len(pbr.BaseColorFactor) == 4 {
+				copy(mat.BaseColor[:], pbr.BaseColorFactor)
+			}
+			if pbr.MetallicFactor != nil {
+				mat.Metallic = *pbr.MetallicFactor
+			}
+			if pbr.RoughnessFactor != nil {
+				mat.Roughness = *pbr.RoughnessFactor
+			}
+			mat.BaseColorTexture = texIndex(pbr.BaseColorTexture)
+			mat.MetallicRoughnessTexture = texIndex(pbr.MetallicRoughnessTexture)
+		}
+		if len(m.EmissiveFactor) == 3 {
+			copy(mat.Emissive[:], m.EmissiveFactor)
+		}
+		if m.AlphaMode != "" {
+			mat.AlphaMode = m.AlphaMode
+		}
+		if m.AlphaCutoff != nil {
+			mat.AlphaCutoff = *m.AlphaCutoff
+		}
+		model.Materials = append(model.Materials, mat)
+	}
+
+	for _, t := range doc.Textures {
+		tex := &GLTFTexture{Image: optIndex(t.Source)}
+		if t.Sampler != nil {
+			if *t.Sampler < 0 || *t.Sampler >= len(doc.Samplers) {
+				return nil, fmt.Errorf("gltf: sampler %d out of range", *t.Sampler)
+			}
+			s := doc.Samplers[*t.Sampler]
+			tex.MagFilter, tex.MinFilter, tex.WrapS, tex.WrapT = s.MagFilter, s.MinFilter, s.WrapS, s.WrapT
+		}
+		model.Textures = append(model.Textures, tex)
+	}
+
+	for i, img := range doc.Images {
+		var data []byte
+		var err error
+		if img.BufferView != nil {
+			data, err = d.bufferView(*img.BufferView)
+		} else {
+			data, err = d.loadURI(img.URI)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("gltf: image %d: %v", i, err)
+		}
+		decoded, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("gltf: image %d: %v", i, err)
+		}
+		model.Images = append(model.Images, decoded)
+	}
+
+	for i, s := range doc.Skins {
+		skin := &GLTFSkin{
+			Name:     s.Name,
+			Joints:   s.Joints,
+			Skeleton: optIndex(s.Skeleton),
+		}
+		for _, j := range s.Joints {
+			if j < 0 || j >= len(model.Nodes) {
+				return nil, fmt.Errorf("gltf: skin %d: joint %d out of range", i, j)
+			}
+		}
+		if skin.Skeleton >= len(model.Nodes) {
+			return nil, fmt.Errorf("gltf: skin %d: skeleton %d out of range", i, skin.Skeleton)
+		}
+		if s.InverseBindMatrices != nil {
+			values, err := d.accessor(*s.InverseBindMatrices, "MAT4")
+			if err != nil {
+				return nil, fmt.Errorf("gltf: skin %d: %v", i, err)
+			}
+			for j := 0; j+16 <= len(values); j += 16 {
+				var m mgl.Mat4
+				copy(m[:], values[j:j+16])
+				skin.InverseBindMatrices = append(skin.InverseBindMatrices, m)
+			}
+			if len(skin.InverseBindMatrices) < len(s.Joints) {
+				return nil, fmt.Errorf("gltf: skin %d: %d inverse bind matrices for %d joints", i, len(skin.InverseBindMatrices), len(s.Joints))
+			}
+		} else {
+			for range s.Joints {
+				skin.InverseBindMatrices = append(skin.InverseBindMatrices, mgl.Ident4())
+			}
+		}
+		model.Skins = append(model.Skins, skin)
+	}
+	for i, n := range model.Nodes {
+		if n.Skin >= len(model.Skins) {
+			return nil, fmt.Errorf("gltf: node %d: skin %d out of range", i, n.Skin)
+		}
+	}
+
+	for i, a := range doc.Animations {
+		anim := &GLTFAnimation{Name: a.Name}
+		for _, c := range a.Channels {
+			if c.Target.Node == nil {
+				continue
+			}
+			if c.Sampler < 0 || c.Sampler >= len(a.Samplers) {
+				return nil, fmt.Errorf("gltf: animation %d: sampler %d out of range", i, c.Sampler)
+			}
+			if *c.Target.Node < 0 || *c.Target.Node >= len(model.Nodes) {
+				return nil, fmt.Errorf("gltf: animation %d: node %d out of range", i, *c.Target.Node)
+			}
+			outType, ok := gltfPathTypes[c.Target.Path]
+			if !ok {
+				return nil, fmt.Errorf("gltf: animation %d: unknown path %q", i, c.Target.Path)
+			}
+			s := a.Samplers[c.Sampler]
+			interpolation := s.Interpolation
+			keys := 1
+			switch interpolation {
+			case "":
+				interpolation = "LINEAR"
+			case "LINEAR", "STEP":
+			case "CUBICSPLINE":
+				keys = 3
+			default:
+				return nil, fmt.Errorf("gltf: animation %d: unknown interpolation %q", i, interpolation)
+			}
+			times, err := d.accessor(s.Input, "SCALAR")
+			if err != nil {
+				return nil, fmt.Errorf("gltf: animation %d: %v", i, err)
+			}
+			if len(times) == 0 {
+				return nil, fmt.Errorf("gltf: animation %d: sampler %d has no keyframes", i, c.Sampler)
+			}
+			values, err := d.accessor(s.Output, outType)
+			if err != nil {
+				return nil, fmt.Errorf("gltf: animation %d: %v", i, err)
+			}
+			// Weights have an element per morph target in each keyframe.
+			n := len(values) / gltfTypeSizes[outType]
+			if n != keys*len(times) && (c.Target.Path != "weights" || n%(keys*len(times)) != 0) {
+				return nil, fmt.Errorf("gltf: animation %d: sampler %d has %d outputs for %d keyframes", i, c.Sampler, n, len(times))
+			}
+			anim.Channels = append(anim.Channels, &GLTFChannel{
+				Node:          *c.Target.Node,
+				Path:          c.Target.Path,
+				Interpolation: interpolation,
+				Times:         times,
+				Values:        values,
+			})
+		}
+		model.Animations = append(model.Animations, anim)
+	}
+
+	return model, nil
+}
+
+func (d *gltfDecoder) loadBuffer(i int, uri string) ([]byte, error) {
+	if uri == "" {
+		if i != 0 || d.bin == nil {
+			return nil, fmt.Errorf("gltf: buffer %d has no data", i)
+		}
+		return d.bin, nil
+	}
+	return d.loadURI(uri)
+}
+
+func (d *gltfDecoder) loadURI(uri string) ([]byte, error) {
+	if strings.HasPrefix(uri, "data:") {
+		i := strings.Index(uri, ";base64,")
+		if i < 0 {
+			return nil, fmt.Errorf("unsupported data URI")
+		}
+		return base64.StdEncoding.DecodeString(uri[i+len(";base64,"):])
+	}
+	if d.openURI == nil {
+		return nil, fmt.Errorf("external URI %q", uri)
+	}
+	f, err := d.openURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+func (d *gltfDecoder) bufferView(i int) ([]byte, error) {
+	if i < 0 || i >= len(d.doc.BufferViews) {
+		return nil, fmt.Errorf("buffer view %d out of range", i)
+	}
+	v := d.doc.BufferViews[i]
+	if v.Buffer < 0 || v.Buffer >= len(d.buffers) {
+		return nil, fmt.Errorf("buffer %d out of range", v.Buffer)
+	}
+	buf := d.buffers[v.Buffer]
+	if v.ByteOffset < 0 || v.ByteLength < 0 || v.ByteOffset+v.ByteLength > len(buf) {
+		return nil, fmt.Errorf("buffer view %d overruns buffer", i)
+	}
+	return buf[v.ByteOffset : v.ByteOffset+v.ByteLength], nil
+}
+
+var gltfTypeSizes = map[string]int{
+	"SCALAR": 1,
+	"VEC2":   2,
+	"VEC3":   3,
+	"VEC4":   4,
+	"MAT2":   4,
+	"MAT3":   9,
+	"MAT4":   16,
+}
+
+// gltfPathTypes are the accessor types of the animated properties.
+var gltfPathTypes = map[string]string{
+	"translation": "VEC3",
+	"rotation":    "VEC4",
+	"scale":       "VEC3",
+	"weights":     "SCALAR",
+}
+
+var gltfComponentSizes = map[int]int{
+	5120: 1, // BYTE
+	5121: 1, // UNSIGNED_BYTE
+	5122: 2, // SHORT
+	5123: 2, // UNSIGNED_SHORT
+	5125: 4, // UNSIGNED_INT
+	5126: 4, // FLOAT
+}
+
+// accessor reads an accessor as floats, normalizing integer components if the
+// accessor says so. If want is set, the accessor type must match it.
+func (d *gltfDecoder) accessor(i int, want string) ([]float32, error) {
+	if i < 0 || i >= len(d.doc.Accessors) {
+		return nil, fmt.Errorf("accessor %d out of range", i)
+	}
+	a := d.doc.Accessors[i]
+	if want != "" && a.Type != want {
+		return nil, fmt.Errorf("accessor %d is %s, want %s", i, a.Type, want)
+	}
+	if a.Sparse != nil {
+		return nil, fmt.Errorf("accessor %d: sparse accessors are not supported", i)
+	}
+	dim, ok := gltfTypeSizes[a.Type]
+	if !ok {
+		return nil, fmt.Errorf("accessor %d: unknown type %q", i, a.Type)
+	}
+	size, ok := gltfComponentSizes[a.ComponentType]
+	if !ok {
+		return nil, fmt.Errorf("accessor %d: unknown component type %d", i, a.ComponentType)
+	}
+	if a.Count < 0 || a.ByteOffset < 0 {
+		return nil, fmt.Errorf("accessor %d: negative count or byte offset", i)
+	}
+
+	r := make([]float32, a.Count*dim)
+	if a.BufferView == nil {
+		// No data means all zeroes.
+		return r, nil
+	}
+	view, err := d.bufferView(*a.BufferView)
+	if err != nil {
+		return nil, err
+	}
+	stride := d.doc.BufferViews[*a.BufferView].ByteStride
+	if stride == 0 {
+		stride = dim * size
+	} else if stride < dim*size {
+		return nil, fmt.Errorf("accessor %d: byte stride %d is less than its element size %d", i, stride, dim*size)
+	}
+	if a.Count > 0 && a.ByteOffset+(a.Count-1)*stride+dim*size > len(view) {
+		return nil, fmt.Errorf("accessor %d overruns its buffer view", i)
+	}
+
+	for n := 0; n < a.Count; n++ {
+		elem := view[a.ByteOffset+n*stride:]
+		for c := 0; c < dim; c++ {
+			b := elem[c*size:]
+			var v float32
+			switch a.ComponentType {
+			case 5120:
+				v = float32(int8(b[0]))
+				if a.Normalized {
+					v = float32(math.Max(float64(v)/127, -1))
+				}
+			case 5121:
+				v = float32(b[0])
+				if a.Normalized {
+					v /= 255
+				}
+			case 5122:
+				v = float32(int16(binary.LittleEndian.Uint16(b)))
+				if a.Normalized {
+					v = float32(math.Max(float64(v)/32767, -1))
+				}
+			case 5123:
+				v = float32(binary.LittleEndian.Uint16(b))
+				if a.Normalized {
+					v /= 65535
+				}
+			case 5125:
+				v = float32(binary.LittleEndian.Uint32(b))
+			case 5126:
+				v = math.Float32frombits(binary.LittleEndian.Uint32(b))
+			}
+			r[n*dim+c] = v
+		}
+	}
+	return r, nil
+}
+
+// indices reads an index accessor without the float conversion, which would
+// lose precision for large unsigned ints.
+func (d *gltfDecoder) indices(i int) ([]uint32, error) {
+	if i < 0 || i >= len(d.doc.Accessors) {
+		return nil, fmt.Errorf("accessor %d out of range", i)
+	}
+	a := d.doc.Accessors[i]
+	if a.Type != "SCALAR" || a.BufferView == nil {
+		return nil, fmt.Errorf("accessor %d is not an index list", i)
+	}
+	view, err := d.bufferView(*a.BufferView)
+	if err != nil {
+		return nil, err
+	}
+	size := gltfComponentSizes[a.ComponentType]
+	if a.ComponentType != 5121 && a.ComponentType != 5123 && a.ComponentType != 5125 {
+		return nil, fmt.Errorf("accessor %d: invalid index component type %d", i, a.ComponentType)
+	}
+	if a.Count < 0 || a.ByteOffset < 0 {
+		return nil, fmt.Errorf("accessor %d: negative count or byte offset", i)
+	}
+	stride := d.doc.BufferViews[*a.BufferView].ByteStride
+	if stride == 0 {
+		stride = size
+	} else if stride < size {
+		return nil, fmt.Errorf("accessor %d: byte stride %d is less than its element size %d", i, stride, size)
+	}
+	if a.Count > 0 && a.ByteOffset+(a.Count-1)*stride+size > len(view) {
+		return nil, fmt.Errorf("accessor %d overruns its buffer view", i)
+	}
+
+	r := make([]uint32, a.Count)
+	for n := range r {
+		b := view[a.ByteOffset+n*stride:]
+		switch size {
+		case 1:
+			r[n] = uint32(b[0])
+		case 2:
+			r[n] = uint32(binary.LittleEndian.Uint16(b))
+		case 4:
+			r[n] = binary.LittleEndian.Uint32(b)
+		}
+	}
+	return r, nil
+}
+
+func (d *gltfDecoder) primitive(attributes map[string]int, indices *int, mode *int) (*GLTFPrimitive, error) {
+	if mode != nil && *mode != 4 {
+		return nil, fmt.Errorf("unsupported mode %d, only triangles are supported", *mode)
+	}
+	prim := &GLTFPrimitive{}
+
+	pos, ok := attributes["POSITION"]
+	if !ok {
+		return nil, fmt.Errorf("no POSITION attribute")
+	}
+	var err error
+	if prim.Positions, err = d.accessor(pos, "VEC3"); err != nil {
+		return nil, err
+	}
+	if i, ok := attributes["NORMAL"]; ok {
+		if prim.Normals, err = d.accessor(i, "VEC3"); err != nil {
+			return nil, err
+		}
+	}
+	if i, ok := attributes["TEXCOORD_0"]; ok {
+		if prim.TexCoords, err = d.accessor(i, "VEC2"); err != nil {
+			return nil, err
+		}
+	}
+	if i, ok := attributes["COLOR_0"]; ok {
+		colors, err := d.accessor(i, "")
+		if err != nil {
+			return nil, err
+		}
+		if d.doc.Accessors[i].Type == "VEC3" {
+			// Expand to RGBA.
+			rgba := make([]float32, 0, len(colors)/3*4)
+			for j := 0; j+3 <= len(colors); j += 3 {
+				rgba = append(rgba, colors[j], colors[j+1], colors[j+2], 1)
+			}
+			colors = rgba
+		}
+		prim.Colors = colors
+	}
+	if i, ok := attributes["JOINTS_0"]; ok {
+		joints, err := d.accessor(i, "VEC4")
+		if err != nil {
+			return nil, err
+		}
+		prim.Joints = make([]uint16, len(joints))
+		for j, v := range joints {
+			prim.Joints[j] = uint16(v)
+		}
+	}
+	if i, ok := attributes["WEIGHTS_0"]; ok {
+		if prim.Weights, err = d.accessor(i, "VEC4"); err != nil {
+			return nil, err
+		}
+	}
+	if indices != nil {
+		if prim.Indices, err = d.indices(*indices); err != nil {
+			return nil, err
+		}
+	}
+	return prim, nil
+}
+
+// decomposeTRS splits an affine transform without shear into translation,
+// rotation and scale.
+func decomposeTRS(m mgl.Mat4) (mgl.Vec3, mgl.Quat, mgl.Vec3) {
+	t := m.Col(3).Vec3()
+	s := mgl.Vec3{m.Col(0).Vec3().Len(), m.Col(1).Vec3().Len(), m.Col(2).Vec3().Len()}
+	if m.Det() < 0 {
+		s[0] = -s[0]
+	}
+	var rot mgl.Mat3
+	for c := 0; c < 3; c++ {
+		col := m.Col(c).Vec3()
+		if s[c] != 0 {
+			col = col.Mul(1 / s[c])
+		}
+		rot.SetCol(c, col)
+	}
+	return t, mgl.Mat4ToQuat(rot.Mat4()).Normalize(), s
+}
@@ -0,0 +1,204 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+const testGLTF = `{
+	"asset": {"version": "2.0"},
+	"scene": 0,
+	"scenes": [{"nodes": [0]}],
+	"nodes": [
+		{"name": "root", "children": [1], "translation": [1, 2, 3]},
+		{"name": "tri", "mesh": 0, "scale": [2, 2, 2]}
+	],
+	"meshes": [{"primitives": [{
+		"attributes": {"POSITION": 0},
+		"indices": 1,
+		"material": 0
+	}]}],
+	"materials": [{
+		"name": "red",
+		"pbrMetallicRoughness": {"baseColorFactor": [1, 0, 0, 1], "metallicFactor": 0.25}
+	}],
+	"accessors": [
+		{"bufferView": 0, "componentType": 5126, "count": 3, "type": "VEC3"},
+		{"bufferView": 1, "componentType": 5123, "count": 3, "type": "SCALAR"},
+		{"bufferView": 2, "componentType": 5126, "count": 2, "type": "SCALAR"},
+		{"bufferView": 2, "byteOffset": 8, "componentType": 5126, "count": 2, "type": "VEC3"}
+	],
+	"bufferViews": [
+		{"buffer": 0, "byteOffset": 0, "byteLength": 36},
+		{"buffer": 0, "byteOffset": 36, "byteLength": 6},
+		{"buffer": 0, "byteOffset": 44, "byteLength": 32}
+	],
+	"buffers": [{"byteLength": 76}],
+	"animations": [{
+		"name": "slide",
+		"channels": [{"sampler": 0, "target": {"node": 0, "path": "translation"}}],
+		"samplers": [{"input": 2, "output": 3}]
+	}]
+}`
+
+// testGLB packs testGLTF and its binary buffer into a GLB container.
+func testGLB() []byte {
+	bin := &bytes.Buffer{}
+	le := binary.LittleEndian
+	binary.Write(bin, le, []float32{0, 0, 0, 1, 0, 0, 0, 1, 0}) // positions
+	binary.Write(bin, le, []uint16{0, 1, 2, 0})                 // indices, padded
+	binary.Write(bin, le, []float32{0, 1})                      // times
+	binary.Write(bin, le, []float32{0, 0, 0, 4, 0, 0})          // translations
+
+	jsonChunk := []byte(testGLTF)
+	for len(jsonChunk)%4 != 0 {
+		jsonChunk = append(jsonChunk, ' ')
+	}
+
+	glb := &bytes.Buffer{}
+	binary.Write(glb, le, []uint32{glbMagic, 2, uint32(12 + 8 + len(jsonChunk) + 8 + bin.Len())})
+	binary.Write(glb, le, []uint32{uint32(len(jsonChunk)), 0x4e4f534a})
+	glb.Write(jsonChunk)
+	binary.Write(glb, le, []uint32{uint32(bin.Len()), 0x004e4942})
+	glb.Write(bin.Bytes())
+	return glb.Bytes()
+}
+
+func TestParseGLB(t *testing.T) {
+	model, err := ParseGLTF(bytes.NewReader(testGLB()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []int{0}; !reflect.DeepEqual(model.Roots, want) {
+		t.Errorf("got roots %v; want %v", model.Roots, want)
+	}
+	if len(model.Nodes) != 2 || model.Nodes[0].Name != "root" || model.Nodes[1].Mesh != 0 {
+		t.Fatalf("unexpected nodes: %+v", model.Nodes)
+	}
+	if got, want := model.Nodes[0].Transform(), mgl.Translate3D(1, 2, 3); !got.ApproxEqual(want) {
+		t.Errorf("got transform %v; want %v", got, want)
+	}
+	if got, want := model.Nodes[1].Transform(), mgl.Scale3D(2, 2, 2); !got.ApproxEqual(want) {
+		t.Errorf("got transform %v; want %v", got, want)
+	}
+
+	prim := model.Meshes[0].Primitives[0]
+	if want := []float32{0, 0, 0, 1, 0, 0, 0, 1, 0}; !reflect.DeepEqual(prim.Positions, want) {
+		t.Errorf("got positions %v; want %v", prim.Positions, want)
+	}
+	if want := []uint32{0, 1, 2}; !reflect.DeepEqual(prim.Indices, want) {
+		t.Errorf("got indices %v; want %v", prim.Indices, want)
+	}
+
+	mat := model.Materials[prim.Material]
+	if mat.BaseColor != (mgl.Vec4{1, 0, 0, 1}) || mat.Metallic != 0.25 || mat.Roughness != 1 || mat.BaseColorTexture != -1 {
+		t.Errorf("unexpected material: %+v", mat)
+	}
+
+	anim := model.Animations[0]
+	if anim.Duration() != 1 {
+		t.Errorf("got duration %v; want 1", anim.Duration())
+	}
+	if got, want := anim.Channels[0].Sample(0.5), []float32{2, 0, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got sample %v; want %v", got, want)
+	}
+}
+
+func TestParseGLBErrors(t *testing.T) {
+	glb := testGLB()
+	if _, err := ParseGLTF(bytes.NewReader(glb[:len(glb)-8]), nil); err == nil {
+		t.Error("expected error for truncated GLB")
+	}
+	if _, err := ParseGLTF(bytes.NewReader([]byte(`{"asset": {"version": "1.0"}}`)), nil); err == nil {
+		t.Error("expected error for glTF 1.0")
+	}
+
+	hierarchies := map[string]string{
+		"cycle":              `[{"children": [1]}, {"children": [2]}, {"children": [1]}]`,
+		"self":               `[{"children": [0]}]`,
+		"shared child":       `[{"children": [2]}, {"children": [2]}, {}]`,
+		"child root":         `[{"children": [1]}, {}], "scenes": [{"nodes": [0, 1]}]`,
+		"child out of range": `[{"children": [3]}]`,
+	}
+	for name, nodes := range hierarchies {
+		doc := `{"asset": {"version": "2.0"}, "nodes": ` + nodes + `}`
+		if _, err := ParseGLTF(strings.NewReader(doc), nil); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	skins := map[string]string{
+		"joint out of range":    `"nodes": [{"skin": 0}], "skins": [{"joints": [0, 1]}]`,
+		"skin out of range":     `"nodes": [{"skin": 1}], "skins": [{"joints": [0]}]`,
+		"few inverse bind mats": `"nodes": [{"skin": 0}, {}], "skins": [{"joints": [0, 1], "inverseBindMatrices": 0}], "accessors": [{"componentType": 5126, "count": 1, "type": "MAT4"}]`,
+	}
+	for name, skin := range skins {
+		doc := `{"asset": {"version": "2.0"}, ` + skin + `}`
+		if _, err := ParseGLTF(strings.NewReader(doc), nil); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	accessors := map[string]string{
+		"valid":           `"count": 3`,
+		"negative count":  `"count": -1`,
+		"negative offset": `"count": 1, "byteOffset": -12`,
+		"negative stride": `"count": 1, "bufferView": 1`,
+		"short stride":    `"count": 3, "bufferView": 2`,
+	}
+	for name, accessor := range accessors {
+		doc := `{"asset": {"version": "2.0"}, "nodes": [{"mesh": 0}],
+			"meshes": [{"primitives": [{"attributes": {"POSITION": 0}}]}],
+			"accessors": [{"bufferView": 0, "componentType": 5126, "type": "VEC3", ` + accessor + `}],
+			"bufferViews": [
+				{"buffer": 0, "byteLength": 48},
+				{"buffer": 0, "byteLength": 48, "byteStride": -12},
+				{"buffer": 0, "byteLength": 48, "byteStride": 4}
+			],
+			"buffers": [{"byteLength": 48, "uri": "data:application/octet-stream;base64,AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}]}`
+		_, err := ParseGLTF(strings.NewReader(doc), nil)
+		if name == "valid" && err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if name != "valid" && err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	// Accessors without a buffer view are zeroes.
+	samplers := map[string]struct {
+		path, sampler string
+		valid         bool
+	}{
+		"rotation":            {"rotation", `{"input": 0, "output": 1}`, true},
+		"cubic translation":   {"translation", `{"input": 0, "output": 3, "interpolation": "CUBICSPLINE"}`, true},
+		"translation as VEC4": {"translation", `{"input": 0, "output": 1}`, false},
+		"too many outputs":    {"translation", `{"input": 0, "output": 2}`, false},
+		"too few cubic":       {"translation", `{"input": 0, "output": 2, "interpolation": "CUBICSPLINE"}`, false},
+		"unknown path":        {"color", `{"input": 0, "output": 2}`, false},
+	}
+	for name, test := range samplers {
+		doc := `{"asset": {"version": "2.0"}, "nodes": [{}],
+			"accessors": [
+				{"componentType": 5126, "count": 2, "type": "SCALAR"},
+				{"componentType": 5126, "count": 2, "type": "VEC4"},
+				{"componentType": 5126, "count": 3, "type": "VEC3"},
+				{"componentType": 5126, "count": 6, "type": "VEC3"}
+			],
+			"animations": [{
+				"channels": [{"sampler": 0, "target": {"node": 0, "path": "` + test.path + `"}}],
+				"samplers": [` + test.sampler + `]
+			}]}`
+		_, err := ParseGLTF(strings.NewReader(doc), nil)
+		if test.valid && err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
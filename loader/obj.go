@@ -0,0 +1,341 @@
+package loader
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"golang.org/x/mobile/asset"
+)
+
+// OBJ is a Wavefront .obj model. Faces are triangulated and split into groups
+// by object, group and material.
+type OBJ struct {
+	Groups    []*OBJGroup
+	Materials map[string]*Material
+}
+
+// OBJGroup is a run of faces sharing a group name and material. Vertex data is
+// interleaving-ready, with one entry per unique vertex, for a StaticShape:
+// Positions are Vec3, TexCoords Vec2, Normals Vec3. TexCoords and Normals are
+// empty if no face in the group has them.
+type OBJGroup struct {
+	Name     string
+	Material *Material
+
+	Positions []float32
+	TexCoords []float32
+	Normals   []float32
+	Indices   []uint32
+
+	// Unique position/texcoord/normal index triples seen so far.
+	vertices map[[3]int]uint32
+}
+
+// Material is a Wavefront .mtl material. Texture maps are paths relative to
+// the .obj file, or asset names when loaded with LoadOBJ.
+type Material struct {
+	Name      string
+	Ambient   mgl.Vec3 // Ka
+	Diffuse   mgl.Vec3 // Kd
+	Specular  mgl.Vec3 // Ks
+	Shininess float32  // Ns
+	Opacity   float32  // d, or 1 - Tr
+
+	DiffuseMap  string // map_Kd
+	SpecularMap string // map_Ks
+	NormalMap   string // map_Bump or bump
+}
+
+// LoadOBJ reads an .obj model and any material libraries it references from
+// the asset repository.
+func LoadOBJ(name string) (*OBJ, error) {
+	f, err := asset.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dir := path.Dir(name)
+	obj, err := ParseOBJ(f, func(lib string) (io.ReadCloser, error) {
+		return asset.Open(path.Join(dir, lib))
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range obj.Materials {
+		for _, p := range []*string{&m.DiffuseMap, &m.SpecularMap, &m.NormalMap} {
+			if *p != "" {
+				*p = path.Join(dir, *p)
+			}
+		}
+	}
+	return obj, nil
+}
+
+// ParseOBJ parses an .obj model. Material libraries are opened with openLib,
+// which may be nil to skip them. Libraries which are missing or fail to parse
+// are logged and skipped, and materials they lack are white placeholders.
+func ParseOBJ(r io.Reader, openLib func(name string) (io.ReadCloser, error)) (*OBJ, error) {
+	obj := &OBJ{Materials: map[string]*Material{}}
+	var positions, texCoords, normals []mgl.Vec3
+	var group *OBJGroup
+	groupName := ""
+	var material *Material
+
+	// newGroup starts a new group unless the current one is still empty.
+	newGroup := func() {
+		if group != nil && len(group.Indices) == 0 {
+			group.Name, group.Material = groupName, material
+			return
+		}
+		group = &OBJGroup{
+			Name:     groupName,
+			Material: material,
+			vertices: map[[3]int]uint32{},
+		}
+		obj.Groups = append(obj.Groups, group)
+	}
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		keyword, args := fields[0], fields[1:]
+
+		var err error
+		switch keyword {
+		case "v":
+			var v mgl.Vec3
+			v, err = parseVec3(args, 3)
+			positions = append(positions, v)
+		case "vt":
+			var v mgl.Vec3
+			v, err = parseVec3(args, 1)
+			texCoords = append(texCoords, v)
+		case "vn":
+			var v mgl.Vec3
+			v, err = parseVec3(args, 3)
+			normals = append(normals, v)
+		case "f":
+			if group == nil {
+				newGroup()
+			}
+			err = group.addFace(args, positions, texCoords, normals)
+		case "g", "o":
+			groupName = strings.Join(args, " ")
+			newGroup()
+		case "usemtl":
+			if len(args) == 0 {
+				err = fmt.Errorf("missing material name")
+				break
+			}
+			m, ok := obj.Materials[args[0]]
+			if !ok {
+				// Keep going with a named placeholder, like most viewers.
+				m = &Material{Name: args[0], Diffuse: mgl.Vec3{1, 1, 1}, Opacity: 1}
+				obj.Materials[args[0]] = m
+			}
+			material = m
+			newGroup()
+		case "mtllib":
+			if openLib == nil {
+				break
+			}
+			for _, lib := range args {
+				if err := loadMTL(obj.Materials, lib, openLib); err != nil {
+					log.Printf("obj: line %d: mtllib: %v", lineNum, err)
+				}
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("obj: line %d: %s: %v", lineNum, keyword, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Drop a trailing group that never got faces.
+	if n := len(obj.Groups); n > 0 && len(obj.Groups[n-1].Indices) == 0 {
+		obj.Groups = obj.Groups[:n-1]
+	}
+	for _, g := range obj.Groups {
+		g.vertices = nil
+		// Libraries may come after the usemtl, replacing its placeholder.
+		if g.Material != nil {
+			g.Material = obj.Materials[g.Material.Name]
+		}
+	}
+	return obj, nil
+}
+
+// addFace triangulates a face as a fan and appends it to the group.
+func (group *OBJGroup) addFace(args []string, positions, texCoords, normals []mgl.Vec3) error {
+	if len(args) < 3 {
+		return fmt.Errorf("face has %d vertices, want at least 3", len(args))
+	}
+	face := make([]uint32, len(args))
+	for i, arg := range args {
+		var ref [3]int // Zero means absent, as OBJ indices are 1-based.
+		parts := strings.Split(arg, "/")
+		if len(parts) > 3 {
+			return fmt.Errorf("invalid vertex %q", arg)
+		}
+		counts := [3]int{len(positions), len(texCoords), len(normals)}
+		for j, part := range parts {
+			if part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return fmt.Errorf("invalid vertex %q", arg)
+			}
+			if n < 0 {
+				n += counts[j] + 1
+			}
+			if n <= 0 || n > counts[j] {
+				return fmt.Errorf("vertex %q out of range", arg)
+			}
+			ref[j] = n
+		}
+		if ref[0] == 0 {
+			return fmt.Errorf("vertex %q has no position", arg)
+		}
+
+		idx, ok := group.vertices[ref]
+		if !ok {
+			idx = uint32(len(group.Positions) / 3)
+			group.vertices[ref] = idx
+			group.addVertex(ref, positions, texCoords, normals)
+		}
+		face[i] = idx
+	}
+
+	for i := 1; i < len(face)-1; i++ {
+		group.Indices = append(group.Indices, face[0], face[i], face[i+1])
+	}
+	return nil
+}
+
+func (group *OBJGroup) addVertex(ref [3]int, positions, texCoords, normals []mgl.Vec3) {
+	n := len(group.Positions) / 3
+	p := positions[ref[0]-1]
+	group.Positions = append(group.Positions, p[0], p[1], p[2])
+
+	// Attributes appear as soon as any vertex has them; earlier vertices are
+	// backfilled with zeroes.
+	if ref[1] != 0 || len(group.TexCoords) > 0 {
+		if len(group.TexCoords) == 0 {
+			group.TexCoords = make([]float32, n*2)
+		}
+		var t mgl.Vec3
+		if ref[1] != 0 {
+			t = texCoords[ref[1]-1]
+		}
+		// OBJ puts V=0 at the bottom of the image, textures are uploaded
+		// top row first.
+		group.TexCoords = append(group.TexCoords, t[0], 1-t[1])
+	}
+	if ref[2] != 0 || len(group.Normals) > 0 {
+		if len(group.Normals) == 0 {
+			group.Normals = make([]float32, n*3)
+		}
+		var v mgl.Vec3
+		if ref[2] != 0 {
+			v = normals[ref[2]-1]
+		}
+		group.Normals = append(group.Normals, v[0], v[1], v[2])
+	}
+}
+
+// loadMTL parses a material library into materials.
+func loadMTL(materials map[string]*Material, name string, openLib func(string) (io.ReadCloser, error)) error {
+	f, err := openLib(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var m *Material
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		keyword, args := fields[0], fields[1:]
+		if keyword == "newmtl" {
+			m = &Material{Name: strings.Join(args, " "), Diffuse: mgl.Vec3{1, 1, 1}, Opacity: 1}
+			materials[m.Name] = m
+			continue
+		}
+		if m == nil {
+			continue
+		}
+
+		var v mgl.Vec3
+		switch keyword {
+		case "Ka":
+			v, err = parseVec3(args, 3)
+			m.Ambient = v
+		case "Kd":
+			v, err = parseVec3(args, 3)
+			m.Diffuse = v
+		case "Ks":
+			v, err = parseVec3(args, 3)
+			m.Specular = v
+		case "Ns":
+			v, err = parseVec3(args, 1)
+			m.Shininess = v[0]
+		case "d":
+			v, err = parseVec3(args, 1)
+			m.Opacity = v[0]
+		case "Tr":
+			v, err = parseVec3(args, 1)
+			m.Opacity = 1 - v[0]
+		case "map_Kd":
+			m.DiffuseMap = mtlMap(name, args)
+		case "map_Ks":
+			m.SpecularMap = mtlMap(name, args)
+		case "map_Bump", "map_bump", "bump":
+			m.NormalMap = mtlMap(name, args)
+		}
+		if err != nil {
+			return fmt.Errorf("mtl %s: line %d: %s: %v", name, lineNum, keyword, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// mtlMap returns the texture file of a map statement, ignoring options, as a
+// path relative to the library.
+func mtlMap(lib string, args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return path.Join(path.Dir(lib), args[len(args)-1])
+}
+
+// parseVec3 parses up to three floats, requiring at least min of them.
+func parseVec3(args []string, min int) (mgl.Vec3, error) {
+	var v mgl.Vec3
+	if len(args) < min {
+		return v, fmt.Errorf("got %d values, want at least %d", len(args), min)
+	}
+	for i := 0; i < len(args) && i < 3; i++ {
+		f, err := strconv.ParseFloat(args[i], 32)
+		if err != nil {
+			return v, err
+		}
+		v[i] = float32(f)
+	}
+	return v, nil
+}
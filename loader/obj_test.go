@@ -0,0 +1,119 @@
+package loader
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+)
+
+const testOBJ = `# A quad and a triangle
+mtllib materials/test.mtl
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+vt 0 0
+vt 1 0
+vt 1 1
+vt 0 1
+vn 0 0 1
+
+g quad
+usemtl red
+f 1/1/1 2/2/1 3/3/1 4/4/1
+
+g tri
+usemtl missing
+f -3//1 -2//1 -1//1
+`
+
+const testMTL = `newmtl red
+Kd 1 0 0
+Ns 32
+d 0.5
+map_Kd red.png
+`
+
+func TestParseOBJ(t *testing.T) {
+	openLib := func(name string) (io.ReadCloser, error) {
+		if name != "materials/test.mtl" {
+			t.Fatalf("opened unexpected library %q", name)
+		}
+		return ioutil.NopCloser(strings.NewReader(testMTL)), nil
+	}
+	obj, err := ParseOBJ(strings.NewReader(testOBJ), openLib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(obj.Groups) != 2 {
+		t.Fatalf("got %d groups; want 2", len(obj.Groups))
+	}
+
+	quad := obj.Groups[0]
+	if quad.Name != "quad" || quad.Material.Name != "red" {
+		t.Errorf("got group %q with material %q", quad.Name, quad.Material.Name)
+	}
+	if want := []uint32{0, 1, 2, 0, 2, 3}; !reflect.DeepEqual(quad.Indices, want) {
+		t.Errorf("got indices %v; want %v", quad.Indices, want)
+	}
+	// V is flipped for top-down textures.
+	if want := []float32{0, 1, 1, 1, 1, 0, 0, 0}; !reflect.DeepEqual(quad.TexCoords, want) {
+		t.Errorf("got texcoords %v; want %v", quad.TexCoords, want)
+	}
+	if len(quad.Normals) != len(quad.Positions) {
+		t.Errorf("got %d normals for %d positions", len(quad.Normals), len(quad.Positions))
+	}
+
+	red := quad.Material
+	if red.Diffuse != (mgl.Vec3{1, 0, 0}) || red.Shininess != 32 || red.Opacity != 0.5 {
+		t.Errorf("got material %+v", red)
+	}
+	if red.DiffuseMap != "materials/red.png" {
+		t.Errorf("got diffuse map %q", red.DiffuseMap)
+	}
+
+	tri := obj.Groups[1]
+	if len(tri.TexCoords) != 0 {
+		t.Errorf("got texcoords %v for a face without them", tri.TexCoords)
+	}
+	if want := []float32{1, 0, 0, 1, 1, 0, 0, 1, 0}; !reflect.DeepEqual(tri.Positions, want) {
+		t.Errorf("got positions %v; want %v", tri.Positions, want)
+	}
+	if tri.Material.Name != "missing" {
+		t.Errorf("got material %q", tri.Material.Name)
+	}
+}
+
+func TestParseOBJLibraries(t *testing.T) {
+	openLib := func(name string) (io.ReadCloser, error) {
+		if name != "test.mtl" {
+			return nil, fmt.Errorf("%s: not found", name)
+		}
+		return ioutil.NopCloser(strings.NewReader(testMTL)), nil
+	}
+	src := "v 0 0 0\nv 1 0 0\nv 0 1 0\nusemtl red\nf 1 2 3\nmtllib missing.mtl test.mtl\n"
+	obj, err := ParseOBJ(strings.NewReader(src), openLib)
+	if err != nil {
+		t.Fatalf("got %v; want the missing library skipped", err)
+	}
+	if got := obj.Groups[0].Material; got != obj.Materials["red"] || got.Diffuse != (mgl.Vec3{1, 0, 0}) {
+		t.Errorf("got placeholder %+v; want the material loaded after usemtl", got)
+	}
+}
+
+func TestParseOBJErrors(t *testing.T) {
+	for _, src := range []string{
+		"v 0 0 0\nf 1 2 3\n",
+		"v 0 0\n",
+		"v 0 0 0\nv 0 0 0\nf 1 2\n",
+	} {
+		if _, err := ParseOBJ(strings.NewReader(src), nil); err == nil {
+			t.Errorf("no error parsing %q", src)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package loader
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	reInclude  = regexp.MustCompile(`^\s*#include\s+"([^"]+)"\s*$`)
+	reVersion  = regexp.MustCompile(`^\s*#version\b`)
+	reLogLine  = regexp.MustCompile(`\b0(?::(\d+)|\((\d+)\))`)
+	reDefineID = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+)
+
+// Source is GLSL source after preprocessing, which remembers where each of its
+// lines came from.
+type Source struct {
+	Text string
+	// Files are the names read, starting with the main file.
+	Files []string
+
+	lines []sourceLine // Origin of each line of Text
+}
+
+type sourceLine struct {
+	file string
+	line int
+}
+
+// Preprocess reads the named GLSL file with open and expands its
+// #include "path" directives, where path is relative to the root that open
+// reads from. Each file is included at most once. Defines are injected after
+// any #version, as NAME or NAME=VALUE.
+func Preprocess(name string, open func(string) ([]byte, error), defines ...string) (*Source, error) {
+	p := preprocessor{
+		open:     open,
+		included: map[string]bool{},
+	}
+	src, err := open(name)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(src), "\n")
+
+	// #version has to come first, after any comments, so defines go after it.
+	first := 0
+	if v := versionLine(lines); v >= 0 {
+		for i := 0; i <= v; i++ {
+			p.emit(lines[i], name, i+1)
+		}
+		first = v + 1
+	}
+	for i, define := range defines {
+		key, value, _ := strings.Cut(define, "=")
+		if !reDefineID.MatchString(key) {
+			return nil, fmt.Errorf("preprocess: invalid define %q", define)
+		}
+		p.emit(strings.TrimSpace("#define "+key+" "+value), "<defines>", i+1)
+	}
+
+	p.included[name] = true
+	p.files = append(p.files, name)
+	if err := p.expand(name, lines, first); err != nil {
+		return nil, err
+	}
+	return &Source{
+		Text:  strings.Join(p.out, "\n"),
+		Files: p.files,
+		lines: p.lines,
+	}, nil
+}
+
+// versionLine returns the index of the #version directive, which may follow
+// blank lines and comments, or -1 if there is none.
+func versionLine(lines []string) int {
+	comment := false // Within a /* */ comment
+	for i, line := range lines {
+		rest := strings.TrimSpace(line)
+		for rest != "" {
+			switch {
+			case comment:
+				end := strings.Index(rest, "*/")
+				if end < 0 {
+					rest = ""
+					continue
+				}
+				comment = false
+				rest = strings.TrimSpace(rest[end+2:])
+			case strings.HasPrefix(rest, "//"):
+				rest = ""
+			case strings.HasPrefix(rest, "/*"):
+				comment = true
+				rest = rest[2:]
+			case reVersion.MatchString(rest):
+				return i
+			default:
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+type preprocessor struct {
+	open     func(string) ([]byte, error)
+	included map[string]bool
+	files    []string
+	out      []string
+	lines    []sourceLine
+}
+
+func (p *preprocessor) emit(text, file string, line int) {
+	p.out = append(p.out, text)
+	p.lines = append(p.lines, sourceLine{file, line})
+}
+
+// expand emits lines of file from first on, recursing into includes.
+func (p *preprocessor) expand(file string, lines []string, first int) error {
+	for i := first; i < len(lines); i++ {
+		m := reInclude.FindStringSubmatch(lines[i])
+		if m == nil {
+			p.emit(lines[i], file, i+1)
+			continue
+		}
+		name := m[1]
+		if p.included[name] {
+			continue
+		}
+		p.included[name] = true
+		p.files = append(p.files, name)
+		src, err := p.open(name)
+		if err != nil {
+			return fmt.Errorf("%s:%d: include: %w", file, i+1, err)
+		}
+		if err := p.expand(name, strings.Split(string(src), "\n"), 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Line returns the file and line which line n (from 1) of Text came from. It
+// returns false for lines out of range, as for sources not preprocessed.
+func (src *Source) Line(n int) (file string, line int, ok bool) {
+	if n < 1 || n > len(src.lines) {
+		return "", 0, false
+	}
+	l := src.lines[n-1]
+	return l.file, l.line, true
+}
+
+// MapLog rewrites the line numbers in a compile log, in the "0:12" and
+// "0(12)" forms drivers use, to the original file and line.
+func (src *Source) MapLog(log string) string {
+	return reLogLine.ReplaceAllStringFunc(log, func(m string) string {
+		sub := reLogLine.FindStringSubmatch(m)
+		n, _ := strconv.Atoi(sub[1] + sub[2])
+		file, line, ok := src.Line(n)
+		if !ok {
+			return m
+		}
+		return fmt.Sprintf("%s:%d", file, line)
+	})
+}
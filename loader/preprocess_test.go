@@ -0,0 +1,104 @@
+package loader
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testOpener(files map[string]string) func(string) ([]byte, error) {
+	return func(name string) ([]byte, error) {
+		src, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: not found", name)
+		}
+		return []byte(src), nil
+	}
+}
+
+func TestPreprocess(t *testing.T) {
+	open := testOpener(map[string]string{
+		"main.f.glsl": strings.Join([]string{
+			"#version 300 es",
+			`#include "common/lighting.glsl"`,
+			`#include "common/fog.glsl"`,
+			"void main() {}",
+		}, "\n"),
+		"common/lighting.glsl": "#include \"common/math.glsl\"\nvec3 light();",
+		"common/fog.glsl":      "#include \"common/math.glsl\"\nfloat fog();",
+		"common/math.glsl":     "float sq(float x);",
+	})
+
+	src, err := Preprocess("main.f.glsl", open, "NormalMap", "MaxLights=8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"#version 300 es",
+		"#define NormalMap",
+		"#define MaxLights 8",
+		"float sq(float x);",
+		"vec3 light();",
+		"float fog();",
+		"void main() {}",
+	}, "\n")
+	if src.Text != want {
+		t.Errorf("got:\n%s\nwant:\n%s", src.Text, want)
+	}
+	if want := []string{"main.f.glsl", "common/lighting.glsl", "common/math.glsl", "common/fog.glsl"}; !reflect.DeepEqual(src.Files, want) {
+		t.Errorf("got files %v; want %v", src.Files, want)
+	}
+
+	log := "ERROR: 0:6: 'fog' : syntax error\n0(5) : error C0000: bad\n0:99: out of range"
+	want = "ERROR: common/fog.glsl:2: 'fog' : syntax error\ncommon/lighting.glsl:2 : error C0000: bad\n0:99: out of range"
+	if got := src.MapLog(log); got != want {
+		t.Errorf("got log:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPreprocessVersionAfterComments(t *testing.T) {
+	open := testOpener(map[string]string{
+		"main.v.glsl": strings.Join([]string{
+			"// Copyright",
+			"",
+			"/* Multi-line",
+			"   comment */",
+			"#version 300 es",
+			"void main() {}",
+		}, "\n"),
+		"plain.v.glsl": "// No version\nvoid main() {}",
+	})
+
+	src, err := Preprocess("main.v.glsl", open, "Fog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(src.Text, "\n")
+	if lines[4] != "#version 300 es" || lines[5] != "#define Fog" {
+		t.Errorf("defines not injected after #version:\n%s", src.Text)
+	}
+	if file, line, _ := src.Line(7); file != "main.v.glsl" || line != 6 {
+		t.Errorf("got line 7 from %s:%d; want main.v.glsl:6", file, line)
+	}
+
+	src, err = Preprocess("plain.v.glsl", open, "Fog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(src.Text, "#define Fog\n") {
+		t.Errorf("defines not first without #version:\n%s", src.Text)
+	}
+}
+
+func TestPreprocessErrors(t *testing.T) {
+	open := testOpener(map[string]string{
+		"main.v.glsl": "void f();\n#include \"missing.glsl\"",
+	})
+	if _, err := Preprocess("main.v.glsl", open); err == nil || !strings.HasPrefix(err.Error(), "main.v.glsl:2: include:") {
+		t.Errorf("got error %v; want the include location", err)
+	}
+	if _, err := Preprocess("main.v.glsl", open, "Bad Name"); err == nil {
+		t.Error("invalid define accepted")
+	}
+}
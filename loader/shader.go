@@ -1,10 +1,14 @@
 package loader
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"sort"
+	"strings"
 
+	mgl "github.com/go-gl/mathgl/mgl32"
 	"golang.org/x/mobile/asset"
 	"golang.org/x/mobile/gl"
 )
@@ -19,6 +23,45 @@ type Shader interface {
 	Attrib(string) gl.Attrib
 	Uniform(string) gl.Uniform
 	Context() gl.Context
+	Program() gl.Program
+
+	// Attribs and Uniforms list the active variables of the linked program.
+	// Arrays of basic types are listed once, named with a [0] suffix, while
+	// arrays of structs are listed per element and member, as lights[1].color.
+	Attribs() []Variable
+	Uniforms() []Variable
+	// LookupAttrib and LookupUniform are Attrib and Uniform for names which
+	// the shader may leave out, such as the optional inputs the engine sets.
+	// They report whether the name is active, and do not record it if not.
+	LookupAttrib(string) (gl.Attrib, bool)
+	LookupUniform(string) (gl.Uniform, bool)
+	// SetStrict makes Attrib and Uniform log names which are not active in
+	// the program, once each.
+	SetStrict(bool)
+	// Check returns an error naming every attribute and uniform looked up
+	// with Attrib or Uniform which is not active in the program, or nil.
+	Check() error
+
+	// The typed setters upload a uniform of the shader, which must be in
+	// use, unless it is not active or was last set to the same value. Like
+	// LookupUniform, they do not record names which are not active. Values
+	// set with gl calls instead are not seen, so a uniform should be set
+	// through one or the other.
+	SetInt(name string, v int)
+	SetFloat(name string, v float32)
+	SetVec3(name string, v mgl.Vec3)
+	SetVec4(name string, v mgl.Vec4)
+	SetMat4(name string, v mgl.Mat4)
+	// SetTexture binds tex to target on a texture unit, and points the
+	// sampler uniform name at the unit.
+	SetTexture(name string, unit int, target gl.Enum, tex gl.Texture)
+}
+
+// Variable is an active attribute or uniform of a linked program.
+type Variable struct {
+	Name string
+	Size int     // Number of array elements, or 1
+	Type gl.Enum // FLOAT_VEC3, SAMPLER_2D, ...
 }
 
 func NewShader(glctx gl.Context, vertAsset, fragAsset string) (Shader, error) {
@@ -26,43 +69,158 @@ func NewShader(glctx gl.Context, vertAsset, fragAsset string) (Shader, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newShader(glctx, program), nil
+}
+
+// NewShaderSource is like NewShader, but compiles the given GLSL sources
+// instead of reading them from the asset repository.
+func NewShaderSource(glctx gl.Context, vertSrc, fragSrc string) (Shader, error) {
+	program, err := newProgram(glctx, &Source{Text: vertSrc}, &Source{Text: fragSrc})
+	if err != nil {
+		return nil, err
+	}
+	return newShader(glctx, program), nil
+}
+
+// newProgram compiles and links a new program from GLSL sources.
+func newProgram(glctx gl.Context, vertSrc, fragSrc *Source) (gl.Program, error) {
+	program := glctx.CreateProgram()
+	if program.Value == 0 {
+		return gl.Program{}, fmt.Errorf("glutil: no programs available")
+	}
 
-	return &shader{
+	vertexShader, err := compileShader(glctx, gl.VERTEX_SHADER, vertSrc)
+	if err != nil {
+		glctx.DeleteProgram(program)
+		return gl.Program{}, err
+	}
+	fragmentShader, err := compileShader(glctx, gl.FRAGMENT_SHADER, fragSrc)
+	if err != nil {
+		glctx.DeleteShader(vertexShader)
+		glctx.DeleteProgram(program)
+		return gl.Program{}, err
+	}
+	if err := linkShaders(glctx, program, vertexShader, fragmentShader); err != nil {
+		glctx.DeleteProgram(program)
+		return gl.Program{}, err
+	}
+	return program, nil
+}
+
+// newShader wraps a linked program.
+func newShader(glctx gl.Context, program gl.Program) *shader {
+	s := &shader{
 		glctx:    glctx,
-		program:  program,
 		attribs:  map[string]gl.Attrib{},
 		uniforms: map[string]gl.Uniform{},
-	}, nil
+		missing:  map[string]bool{},
+		cache:    newUniformCache(),
+	}
+	s.setProgram(program)
+	return s
 }
 
 type shader struct {
 	glctx   gl.Context
 	program gl.Program
 
+	// Asset name, preprocessor defines and the files read, when loaded by a
+	// Shaders.
+	name    string
+	defines []string
+	files   []string
+
 	attribs  map[string]gl.Attrib
 	uniforms map[string]gl.Uniform
+
+	activeAttribs  []Variable
+	activeUniforms []Variable
+	strict         bool
+	missing        map[string]bool // By kind and name, as "uniform color"
+
+	cache uniformCache
 }
 
 func (shader *shader) Context() gl.Context {
 	return shader.glctx
 }
 
+func (shader *shader) Program() gl.Program {
+	return shader.program
+}
+
 func (shader *shader) Attrib(name string) gl.Attrib {
+	v, ok := shader.LookupAttrib(name)
+	if !ok {
+		shader.notFound("attribute", name)
+	}
+	return v
+}
+
+func (shader *shader) Uniform(name string) gl.Uniform {
+	v, ok := shader.LookupUniform(name)
+	if !ok {
+		shader.notFound("uniform", name)
+	}
+	return v
+}
+
+func (shader *shader) LookupAttrib(name string) (gl.Attrib, bool) {
 	v, ok := shader.attribs[name]
 	if !ok {
 		v = shader.glctx.GetAttribLocation(shader.program, name)
 		shader.attribs[name] = v
 	}
-	return v
+	return v, int32(v.Value) != -1
 }
 
-func (shader *shader) Uniform(name string) gl.Uniform {
+func (shader *shader) LookupUniform(name string) (gl.Uniform, bool) {
 	v, ok := shader.uniforms[name]
 	if !ok {
 		v = shader.glctx.GetUniformLocation(shader.program, name)
 		shader.uniforms[name] = v
 	}
-	return v
+	return v, v.Value != -1
+}
+
+// notFound records a lookup of a name which is not active, logging it the
+// first time in strict mode.
+func (shader *shader) notFound(kind, name string) {
+	key := kind + " " + name
+	seen := shader.missing[key]
+	shader.missing[key] = true
+	if seen || !shader.strict {
+		return
+	}
+	if shader.name != "" {
+		log.Printf("shader %s: no active %s %q", variantKey(shader.name, shader.defines), kind, name)
+	} else {
+		log.Printf("shader %d: no active %s %q", shader.program.Value, kind, name)
+	}
+}
+
+func (shader *shader) Attribs() []Variable {
+	return shader.activeAttribs
+}
+
+func (shader *shader) Uniforms() []Variable {
+	return shader.activeUniforms
+}
+
+func (shader *shader) SetStrict(strict bool) {
+	shader.strict = strict
+}
+
+func (shader *shader) Check() error {
+	if len(shader.missing) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(shader.missing))
+	for key := range shader.missing {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("shader: not active: %s", strings.Join(names, ", "))
 }
 
 func (shader *shader) Use() {
@@ -74,10 +232,57 @@ func (shader *shader) Close() error {
 	return nil
 }
 
+// replace swaps in a newly linked program, deleting the old one.
+func (shader *shader) replace(program gl.Program) {
+	shader.glctx.DeleteProgram(shader.program)
+	for name := range shader.attribs {
+		delete(shader.attribs, name)
+	}
+	for name := range shader.uniforms {
+		delete(shader.uniforms, name)
+	}
+	for key := range shader.missing {
+		delete(shader.missing, key)
+	}
+	shader.cache.reset()
+	shader.setProgram(program)
+}
+
+// setProgram uses program and lists its active variables.
+func (shader *shader) setProgram(program gl.Program) {
+	glctx := shader.glctx
+	shader.program = program
+	shader.activeAttribs = shader.activeAttribs[:0]
+	for i := 0; i < glctx.GetProgrami(program, gl.ACTIVE_ATTRIBUTES); i++ {
+		name, size, ty := glctx.GetActiveAttrib(program, uint32(i))
+		shader.activeAttribs = append(shader.activeAttribs, Variable{name, size, ty})
+	}
+	shader.activeUniforms = shader.activeUniforms[:0]
+	for i := 0; i < glctx.GetProgrami(program, gl.ACTIVE_UNIFORMS); i++ {
+		name, size, ty := glctx.GetActiveUniform(program, uint32(i))
+		shader.activeUniforms = append(shader.activeUniforms, Variable{name, size, ty})
+	}
+}
+
 type Shaders interface {
 	Load(...string) error
 	Get(string) Shader
-	Reload() error
+	// Variant returns the named shader compiled with preprocessor defines,
+	// as NAME or NAME=VALUE, compiling it on first use. The order of defines
+	// does not matter.
+	Variant(name string, defines ...string) (Shader, error)
+	// Names returns the names of the loaded shaders, sorted.
+	Names() []string
+	// Files returns the assets read to compile the named shader, including
+	// any #includes.
+	Files(string) []string
+	// SetStrict calls SetStrict on every shader, including those loaded
+	// later.
+	SetStrict(bool)
+	// Reload recompiles the named shaders with all of their variants, or all
+	// of them if none are named. Shaders which fail keep their last good
+	// program.
+	Reload(...string) error
 	Close() error
 }
 
@@ -85,25 +290,68 @@ func ShaderLoader(glctx gl.Context) *shaderLoader {
 	return &shaderLoader{
 		glctx:   glctx,
 		shaders: map[string]*shader{},
+		open:    loadAsset,
 	}
 }
 
 type shaderLoader struct {
 	glctx   gl.Context
-	shaders map[string]*shader
+	shaders map[string]*shader // By variantKey
+	open    func(name string) ([]byte, error)
+	strict  bool
+}
+
+func (loader *shaderLoader) SetStrict(strict bool) {
+	loader.strict = strict
+	for _, shader := range loader.shaders {
+		shader.SetStrict(strict)
+	}
+}
+
+// variantKey identifies a shader compiled with sorted defines.
+func variantKey(name string, defines []string) string {
+	if len(defines) == 0 {
+		return name
+	}
+	return name + "#" + strings.Join(defines, ",")
+}
+
+// compile builds a new program from the sources of the named shader. It
+// returns the names of the files read, for watching.
+func (loader *shaderLoader) compile(name string, defines []string) (gl.Program, []string, error) {
+	vertSrc, err := Preprocess(fmt.Sprintf("%s.v.glsl", name), loader.open, defines...)
+	if err != nil {
+		return gl.Program{}, nil, err
+	}
+	fragSrc, err := Preprocess(fmt.Sprintf("%s.f.glsl", name), loader.open, defines...)
+	if err != nil {
+		return gl.Program{}, nil, err
+	}
+	files := append(vertSrc.Files, fragSrc.Files...)
+	program, err := newProgram(loader.glctx, vertSrc, fragSrc)
+	return program, files, err
+}
+
+// load compiles a shader and adds it to the loader.
+func (loader *shaderLoader) load(name string, defines []string) (*shader, error) {
+	key := variantKey(name, defines)
+	log.Println("Loading shader:", key)
+	program, files, err := loader.compile(name, defines)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	s := newShader(loader.glctx, program)
+	s.name, s.defines, s.files = name, defines, files
+	s.strict = loader.strict
+	loader.shaders[key] = s
+	return s, nil
 }
 
 func (loader *shaderLoader) Load(names ...string) error {
 	for _, name := range names {
-		s, err := NewShader(
-			loader.glctx,
-			fmt.Sprintf("%s.v.glsl", name),
-			fmt.Sprintf("%s.f.glsl", name),
-		)
-		if err != nil {
+		if _, err := loader.load(name, nil); err != nil {
 			return err
 		}
-		loader.shaders[name] = s.(*shader)
 	}
 	return nil
 }
@@ -112,19 +360,89 @@ func (loader *shaderLoader) Get(name string) Shader {
 	return loader.shaders[name]
 }
 
-func (loader *shaderLoader) Reload() error {
-	for k, shader := range loader.shaders {
-		err := LoadShaders(
-			loader.glctx,
-			shader.program,
-			fmt.Sprintf("%s.v.glsl", k),
-			fmt.Sprintf("%s.f.glsl", k),
-		)
-		if err != nil {
-			return err
+func (loader *shaderLoader) Variant(name string, defines ...string) (Shader, error) {
+	defines = append([]string(nil), defines...)
+	sort.Strings(defines)
+	if s, ok := loader.shaders[variantKey(name, defines)]; ok {
+		return s, nil
+	}
+	s, err := loader.load(name, defines)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (loader *shaderLoader) Names() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, shader := range loader.shaders {
+		if !seen[shader.name] {
+			seen[shader.name] = true
+			names = append(names, shader.name)
 		}
 	}
-	return nil
+	sort.Strings(names)
+	return names
+}
+
+func (loader *shaderLoader) Files(name string) []string {
+	seen := map[string]bool{}
+	var files []string
+	for _, key := range loader.keys() {
+		shader := loader.shaders[key]
+		if shader.name != name {
+			continue
+		}
+		for _, file := range shader.files {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+// keys returns the variant keys of the loaded shaders, sorted.
+func (loader *shaderLoader) keys() []string {
+	keys := make([]string, 0, len(loader.shaders))
+	for key := range loader.shaders {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (loader *shaderLoader) Reload(names ...string) error {
+	if len(names) == 0 {
+		names = loader.Names()
+	}
+	var errs []error
+	for _, name := range names {
+		found := false
+		for _, key := range loader.keys() {
+			shader := loader.shaders[key]
+			if shader.name != name {
+				continue
+			}
+			found = true
+			program, files, err := loader.compile(name, shader.defines)
+			if files != nil {
+				// Includes may have changed even if the shader failed.
+				shader.files = files
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				continue
+			}
+			shader.replace(program)
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("%s: shader not loaded", name))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 func (loader *shaderLoader) Close() error {
@@ -144,24 +462,32 @@ func loadAsset(name string) ([]byte, error) {
 
 func loadShader(glctx gl.Context, shaderType gl.Enum, assetName string) (gl.Shader, error) {
 	// Borrowed from golang.org/x/mobile/exp/gl/glutil
-	src, err := loadAsset(assetName)
+	src, err := Preprocess(assetName, loadAsset)
 	if err != nil {
 		return gl.Shader{}, err
 	}
+	return compileShader(glctx, shaderType, src)
+}
 
+// compileShader compiles preprocessed source, with line numbers in errors
+// mapped back to the original files.
+func compileShader(glctx gl.Context, shaderType gl.Enum, src *Source) (gl.Shader, error) {
 	shader := glctx.CreateShader(shaderType)
 	if shader.Value == 0 {
 		return gl.Shader{}, fmt.Errorf("glutil: could not create shader (type %v)", shaderType)
 	}
-	glctx.ShaderSource(shader, string(src))
+	glctx.ShaderSource(shader, src.Text)
 	glctx.CompileShader(shader)
 	if glctx.GetShaderi(shader, gl.COMPILE_STATUS) == 0 {
 		defer glctx.DeleteShader(shader)
-		return gl.Shader{}, fmt.Errorf("shader compile: %s", glctx.GetShaderInfoLog(shader))
+		return gl.Shader{}, fmt.Errorf("shader compile: %s", src.MapLog(glctx.GetShaderInfoLog(shader)))
 	}
 	return shader, nil
 }
 
+// LoadShaders compiles shader assets and links them into program, replacing
+// any previous link. On failure the program is left unusable, for the caller
+// to delete.
 func LoadShaders(glctx gl.Context, program gl.Program, vertexAsset, fragmentAsset string) error {
 	vertexShader, err := loadShader(glctx, gl.VERTEX_SHADER, vertexAsset)
 	if err != nil {
@@ -172,7 +498,10 @@ func LoadShaders(glctx gl.Context, program gl.Program, vertexAsset, fragmentAsse
 		glctx.DeleteShader(vertexShader)
 		return err
 	}
+	return linkShaders(glctx, program, vertexShader, fragmentShader)
+}
 
+func linkShaders(glctx gl.Context, program gl.Program, vertexShader, fragmentShader gl.Shader) error {
 	if glctx.GetProgrami(program, gl.ATTACHED_SHADERS) > 0 {
 		for _, shader := range glctx.GetAttachedShaders(program) {
 			glctx.DetachShader(program, shader)
@@ -188,7 +517,6 @@ func LoadShaders(glctx gl.Context, program gl.Program, vertexAsset, fragmentAsse
 	glctx.DeleteShader(fragmentShader)
 
 	if glctx.GetProgrami(program, gl.LINK_STATUS) == 0 {
-		defer glctx.DeleteProgram(program)
 		return fmt.Errorf("LoadShaders: %s", glctx.GetProgramInfoLog(program))
 	}
 	return nil
@@ -205,5 +533,9 @@ func LoadProgram(glctx gl.Context, vertexAsset, fragmentAsset string) (program g
 	}
 
 	err = LoadShaders(glctx, program, vertexAsset, fragmentAsset)
+	if err != nil {
+		glctx.DeleteProgram(program)
+		return gl.Program{}, err
+	}
 	return
 }
@@ -0,0 +1,144 @@
+package loader
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/shazow/go-gameblocks/gltest"
+	"golang.org/x/mobile/gl"
+)
+
+func TestShaderVariants(t *testing.T) {
+	glctx := gltest.NewContext()
+	shaders := ShaderLoader(glctx)
+	shaders.open = testOpener(map[string]string{
+		"lit.v.glsl": "void main() {}",
+		"lit.f.glsl": "#ifdef Fog\nvoid fog();\n#endif\nvoid main() {}",
+	})
+	if err := shaders.Load("lit"); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := shaders.Variant("lit", "Textured", "Fog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := shaders.Variant("lit", "Fog", "Textured")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Error("same defines in another order compiled a new variant")
+	}
+	if a == shaders.Get("lit") {
+		t.Error("variant is the base shader")
+	}
+	src := glctx.GetShaderSource(glctx.GetAttachedShaders(a.Program())[1])
+	if !strings.HasPrefix(src, "#define Fog\n#define Textured\n") {
+		t.Errorf("defines not injected:\n%s", src)
+	}
+	if names := shaders.Names(); len(names) != 1 || names[0] != "lit" {
+		t.Errorf("got names %v; want [lit]", names)
+	}
+
+	before := []uint32{shaders.Get("lit").Program().Value, a.Program().Value}
+	if err := shaders.Reload("lit"); err != nil {
+		t.Fatal(err)
+	}
+	after := []uint32{shaders.Get("lit").Program().Value, a.Program().Value}
+	for i := range before {
+		if before[i] == after[i] {
+			t.Errorf("shader %d not reloaded", i)
+		}
+	}
+	if err := shaders.Reload("missing"); err == nil {
+		t.Error("reloading a missing shader succeeded")
+	}
+}
+
+func TestShaderReflection(t *testing.T) {
+	glctx := gltest.NewContext()
+	shader, err := NewShaderSource(glctx, `
+uniform mat4 normalMatrix;
+uniform vec3 colors[4];
+attribute vec3 vertCoord;
+void main() {}
+`, "void main() {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := shader.Attribs(), []Variable{{"vertCoord", 1, gl.FLOAT_VEC3}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got attribs %v; want %v", got, want)
+	}
+	want := []Variable{{"normalMatrix", 1, gl.FLOAT_MAT4}, {"colors[0]", 4, gl.FLOAT_VEC3}}
+	if got := shader.Uniforms(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got uniforms %v; want %v", got, want)
+	}
+
+	shader.SetStrict(true)
+	shader.Uniform("normalMatrix")
+	shader.Uniform("colors[3]")
+	if err := shader.Check(); err != nil {
+		t.Errorf("got %v for active names", err)
+	}
+	shader.Uniform("normalMat")
+	shader.Attrib("normalMat")
+	shader.Attrib("vertColor")
+	err = shader.Check()
+	if err == nil || !strings.Contains(err.Error(), "attribute normalMat, attribute vertColor, uniform normalMat") {
+		t.Errorf("got %v; want the misspelled names", err)
+	}
+}
+
+func TestShaderSetters(t *testing.T) {
+	glctx := gltest.NewContext()
+	shaders := ShaderLoader(glctx)
+	shaders.open = testOpener(map[string]string{
+		"flat.v.glsl": "uniform mat4 model;\nvoid main() {}",
+		"flat.f.glsl": "uniform sampler2D texSampler;\nvoid main() {}",
+	})
+	if err := shaders.Load("flat"); err != nil {
+		t.Fatal(err)
+	}
+	shader := shaders.Get("flat")
+
+	model := mgl.Translate3D(1, 2, 3)
+	shader.SetMat4("model", model)
+	shader.SetMat4("model", model)
+	if got := len(glctx.Filter("UniformMatrix4fv")); got != 1 {
+		t.Errorf("got %d uploads of the same value; want 1", got)
+	}
+	shader.SetMat4("model", mgl.Ident4())
+	if got := len(glctx.Filter("UniformMatrix4fv")); got != 2 {
+		t.Errorf("got %d uploads after changing the value; want 2", got)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { shader.SetMat4("model", mgl.Ident4()) }); allocs != 0 {
+		t.Errorf("got %v allocations setting the same value; want 0", allocs)
+	}
+	shader.SetFloat("missing", 1)
+	if got := len(glctx.Filter("Uniform1f")); got != 0 {
+		t.Errorf("got %d uploads to an inactive uniform; want 0", got)
+	}
+
+	shader.SetTexture("texSampler", 1, gl.TEXTURE_2D, gl.Texture{Value: 7})
+	shader.SetTexture("texSampler", 1, gl.TEXTURE_2D, gl.Texture{Value: 8})
+	if got := len(glctx.Filter("BindTexture")); got != 2 {
+		t.Errorf("got %d texture binds; want 2", got)
+	}
+	if got := len(glctx.Filter("Uniform1i")); got != 1 {
+		t.Errorf("got %d sampler uploads for the same unit; want 1", got)
+	}
+
+	// Relinking loses uniform values, so they are uploaded again.
+	if err := shaders.Reload("flat"); err != nil {
+		t.Fatal(err)
+	}
+	glctx.Reset()
+	shader.SetMat4("model", mgl.Ident4())
+	if got := len(glctx.Filter("UniformMatrix4fv")); got != 1 {
+		t.Errorf("got %d uploads after reloading; want 1", got)
+	}
+}
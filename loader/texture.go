@@ -42,10 +42,16 @@ func (loader *textureLoader) loadAsset(name string) (*image.RGBA, error) {
 	if err != nil {
 		return nil, err
 	}
+	return toRGBA(img), nil
+}
 
-	rgba := image.NewRGBA(img.Bounds())
-	image_draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, image_draw.Src)
-	return rgba, nil
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	image_draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, image_draw.Src)
+	return rgba
 }
 
 func (loader *textureLoader) Load(names ...string) error {
@@ -60,9 +66,13 @@ func (loader *textureLoader) Load(names ...string) error {
 }
 
 func (loader *textureLoader) Get2D(name string) gl.Texture {
-	glctx := loader.glctx
+	return NewTexture2D(loader.glctx, loader.images[name])
+}
+
+// NewTexture2D uploads img as a 2D texture, bound to TEXTURE0.
+func NewTexture2D(glctx gl.Context, src image.Image) gl.Texture {
 	tex := glctx.CreateTexture()
-	img := loader.images[name]
+	img := toRGBA(src)
 
 	glctx.ActiveTexture(gl.TEXTURE0)
 	glctx.BindTexture(gl.TEXTURE_2D, tex)
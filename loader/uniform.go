@@ -0,0 +1,80 @@
+package loader
+
+import (
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"golang.org/x/mobile/gl"
+)
+
+// uniformCache holds the values last set through a shader's typed setters,
+// by location, so that setting the same value again is skipped.
+type uniformCache struct {
+	ints   map[int32]int
+	floats map[int32]float32
+	vec3s  map[int32]mgl.Vec3
+	vec4s  map[int32]mgl.Vec4
+	mat4s  map[int32]mgl.Mat4
+}
+
+func newUniformCache() uniformCache {
+	return uniformCache{
+		ints:   map[int32]int{},
+		floats: map[int32]float32{},
+		vec3s:  map[int32]mgl.Vec3{},
+		vec4s:  map[int32]mgl.Vec4{},
+		mat4s:  map[int32]mgl.Mat4{},
+	}
+}
+
+// reset forgets every value, as when the program is relinked.
+func (c *uniformCache) reset() {
+	*c = newUniformCache()
+}
+
+// setCached records v for loc, returning false if loc already has the value.
+func setCached[T comparable](values map[int32]T, loc gl.Uniform, v T) bool {
+	if old, ok := values[loc.Value]; ok && old == v {
+		return false
+	}
+	values[loc.Value] = v
+	return true
+}
+
+func (shader *shader) SetInt(name string, v int) {
+	if loc, ok := shader.LookupUniform(name); ok && setCached(shader.cache.ints, loc, v) {
+		shader.glctx.Uniform1i(loc, v)
+	}
+}
+
+func (shader *shader) SetFloat(name string, v float32) {
+	if loc, ok := shader.LookupUniform(name); ok && setCached(shader.cache.floats, loc, v) {
+		shader.glctx.Uniform1f(loc, v)
+	}
+}
+
+func (shader *shader) SetVec3(name string, v mgl.Vec3) {
+	if loc, ok := shader.LookupUniform(name); ok && setCached(shader.cache.vec3s, loc, v) {
+		// Copied so only uploads move the value to the heap.
+		upload := v
+		shader.glctx.Uniform3fv(loc, upload[:])
+	}
+}
+
+func (shader *shader) SetVec4(name string, v mgl.Vec4) {
+	if loc, ok := shader.LookupUniform(name); ok && setCached(shader.cache.vec4s, loc, v) {
+		upload := v
+		shader.glctx.Uniform4fv(loc, upload[:])
+	}
+}
+
+func (shader *shader) SetMat4(name string, v mgl.Mat4) {
+	if loc, ok := shader.LookupUniform(name); ok && setCached(shader.cache.mat4s, loc, v) {
+		upload := v
+		shader.glctx.UniformMatrix4fv(loc, upload[:])
+	}
+}
+
+func (shader *shader) SetTexture(name string, unit int, target gl.Enum, tex gl.Texture) {
+	shader.glctx.ActiveTexture(gl.TEXTURE0 + gl.Enum(unit))
+	shader.glctx.BindTexture(target, tex)
+	shader.SetInt(name, unit)
+}
@@ -0,0 +1,102 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultWatchInterval is how often a ShaderWatcher checks for changes.
+const DefaultWatchInterval = 500 * time.Millisecond
+
+// ShaderWatcher reloads shaders whose sources, including #includes, change on
+// disk, for iterating on them while the game runs. It polls modification
+// times, so it works wherever the assets are plain files, and Poll must be
+// called on the GL thread, such as once per frame.
+type ShaderWatcher struct {
+	// Interval is the least time between checks.
+	Interval time.Duration
+
+	shaders  Shaders
+	dir      string
+	modified map[string]time.Time // By file path
+	errs     map[string]error     // By shader name
+	last     time.Time
+}
+
+// NewShaderWatcher returns a ShaderWatcher for the sources of shaders in
+// dir, which is usually the "assets" directory.
+func NewShaderWatcher(shaders Shaders, dir string) *ShaderWatcher {
+	return &ShaderWatcher{
+		Interval: DefaultWatchInterval,
+		shaders:  shaders,
+		dir:      dir,
+		modified: map[string]time.Time{},
+		errs:     map[string]error{},
+	}
+}
+
+// Poll reloads the shaders whose sources changed since the last check. It
+// returns true if any were reloaded, successfully or not, in which case Err
+// has changed.
+func (w *ShaderWatcher) Poll() bool {
+	now := time.Now()
+	if now.Sub(w.last) < w.Interval {
+		return false
+	}
+	w.last = now
+
+	// Files may be shared by several shaders, so compare them all against the
+	// previous check before recording any.
+	var changed []string
+	modified := map[string]time.Time{}
+	for _, name := range w.shaders.Names() {
+		stale := false
+		for _, file := range w.shaders.Files(name) {
+			path := filepath.Join(w.dir, file)
+			info, err := os.Stat(path)
+			if err != nil {
+				// Editors may briefly remove a file while saving it.
+				continue
+			}
+			last, seen := w.modified[path]
+			if seen && !info.ModTime().Equal(last) {
+				stale = true
+			}
+			modified[path] = info.ModTime()
+		}
+		if stale {
+			changed = append(changed, name)
+		}
+	}
+	for path, t := range modified {
+		w.modified[path] = t
+	}
+
+	for _, name := range changed {
+		if err := w.shaders.Reload(name); err != nil {
+			w.errs[name] = err
+		} else {
+			delete(w.errs, name)
+		}
+	}
+	return len(changed) > 0
+}
+
+// Err returns the errors of shaders which failed to reload and have not
+// reloaded successfully since, or nil.
+func (w *ShaderWatcher) Err() error {
+	var errs []error
+	for _, name := range w.shaders.Names() {
+		if err, ok := w.errs[name]; ok {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (w *ShaderWatcher) String() string {
+	return fmt.Sprintf("<ShaderWatcher %s; %d files>", w.dir, len(w.modified))
+}
@@ -0,0 +1,83 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shazow/go-gameblocks/gltest"
+)
+
+func TestShaderWatcher(t *testing.T) {
+	dir := t.TempDir()
+	modified := time.Now()
+	write := func(name, src string) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// Filesystem timestamps can be too coarse to tell quick writes apart.
+		modified = modified.Add(time.Second)
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.v.glsl", "#include \"common.glsl\"\nvoid main() {}")
+	write("common.glsl", "float f();")
+	write("a.f.glsl", "void main() {}")
+
+	glctx := gltest.NewContext()
+	glctx.CompileError = func(src string) string {
+		if strings.Contains(src, "typo") {
+			return "0:1: syntax error"
+		}
+		return ""
+	}
+	shaders := ShaderLoader(glctx)
+	shaders.open = func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(dir, name))
+	}
+	if err := shaders.Load("a"); err != nil {
+		t.Fatal(err)
+	}
+	shader := shaders.Get("a")
+	good := shader.Program()
+
+	w := NewShaderWatcher(shaders, dir)
+	w.Interval = 0
+	if w.Poll() {
+		t.Error("first poll reported changes")
+	}
+
+	write("a.f.glsl", "typo")
+	if !w.Poll() {
+		t.Fatal("change not detected")
+	}
+	if err := w.Err(); err == nil || !strings.Contains(err.Error(), "syntax error") {
+		t.Errorf("got error %v; want the compile error", err)
+	}
+	if shader.Program() != good || !glctx.IsProgram(good) {
+		t.Error("last good program was not kept")
+	}
+
+	write("a.f.glsl", "void main() { }")
+	if !w.Poll() {
+		t.Fatal("fix not detected")
+	}
+	if err := w.Err(); err != nil {
+		t.Errorf("got error %v after fixing", err)
+	}
+	if shader.Program() == good || glctx.IsProgram(good) {
+		t.Error("old program was not replaced")
+	}
+	if w.Poll() {
+		t.Error("unchanged files reported changes")
+	}
+
+	write("common.glsl", "float f(); // typo")
+	if !w.Poll() || w.Err() == nil {
+		t.Error("change to an include not reloaded")
+	}
+}
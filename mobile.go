@@ -1,6 +1,7 @@
 package gameblocks
 
 import (
+	"github.com/shazow/go-gameblocks/event"
 	"golang.org/x/mobile/app"
 	"golang.org/x/mobile/event/key"
 	"golang.org/x/mobile/event/lifecycle"
@@ -13,6 +14,20 @@ import (
 // StartMobile launches the Engine using the x/mobile backend, should be run
 // from the main thread.
 func StartMobile(engine Engine) {
+	if err := Start(MobilePlatform(), engine); err != nil {
+		panic(err)
+	}
+}
+
+// MobilePlatform returns the Platform for the golang.org/x/mobile backend.
+func MobilePlatform() Platform {
+	return mobilePlatform{}
+}
+
+type mobilePlatform struct{}
+
+func (mobilePlatform) Run(handle func(e interface{}) error) error {
+	var err error
 	app.Main(func(a app.App) {
 		var glctx gl.Context
 		for e := range a.Events() {
@@ -21,12 +36,10 @@ func StartMobile(engine Engine) {
 				switch e.Crosses(lifecycle.StageVisible) {
 				case lifecycle.CrossOn:
 					glctx, _ = e.DrawContext.(gl.Context)
-					if err := engine.Start(glctx); err != nil {
-						panic(err)
-					}
+					err = handle(event.Lifecycle{Stage: event.StageVisible, GL: glctx})
 					a.Send(paint.Event{})
 				case lifecycle.CrossOff:
-					engine.Stop()
+					err = handle(event.Lifecycle{Stage: event.StageHidden})
 					glctx = nil
 				}
 			case paint.Event:
@@ -36,18 +49,42 @@ func StartMobile(engine Engine) {
 					// events sent by the system.
 					continue
 				}
-				engine.Draw()
+				err = handle(event.Paint{})
 				a.Publish()
 				// Drive the animation by preparing to paint the next frame
 				// after this one is shown.
 				a.Send(paint.Event{})
 			case size.Event:
-				engine.Resize(e)
+				err = handle(event.Resize{
+					WidthPx:     e.WidthPx,
+					HeightPx:    e.HeightPx,
+					PixelsPerPt: e.PixelsPerPt,
+				})
 			case touch.Event:
-				engine.Touch(e)
+				err = handle(event.Pointer{
+					X:    e.X,
+					Y:    e.Y,
+					ID:   int64(e.Sequence),
+					Type: mobilePointerTypes[e.Type],
+				})
 			case key.Event:
-				engine.Press(e)
+				err = handle(event.Key{
+					Rune:      e.Rune,
+					Code:      event.Code(e.Code),
+					Modifiers: event.Modifiers(e.Modifiers),
+					Direction: event.Direction(e.Direction),
+				})
+			}
+			if err != nil {
+				return
 			}
 		}
 	})
+	return err
+}
+
+var mobilePointerTypes = map[touch.Type]event.PointerType{
+	touch.TypeBegin: event.PointerDown,
+	touch.TypeMove:  event.PointerMove,
+	touch.TypeEnd:   event.PointerUp,
 }
@@ -0,0 +1,224 @@
+package gameblocks
+
+import (
+	"fmt"
+	"image"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/shazow/go-gameblocks/loader"
+	"golang.org/x/mobile/gl"
+)
+
+// Model is a glTF model built into a tree of Nodes. Nodes are indexed like
+// the glTF nodes they were built from, so they can be animated.
+//
+// Each mesh primitive is a child Node with a StaticShape, drawn with the base
+// color texture and these uniforms from its material:
+//
+//	uniform struct {
+//		vec4 baseColor;
+//		float metallic;
+//		float roughness;
+//		vec3 emissive;
+//	} material;
+//
+// Primitives without a material get the glTF default, a white, fully metallic
+// and rough one, and those without a base color texture get a white texture.
+//
+// Skinned primitives also have vertJoints and vertWeights attributes, for
+// blending by JointMatrices.
+type Model struct {
+	Root     *Node
+	Nodes    []*Node
+	Textures []gl.Texture
+	GLTF     *loader.GLTF
+
+	// Local transforms of Nodes, kept apart for animation.
+	translations []mgl.Vec3
+	rotations    []mgl.Quat
+	scales       []mgl.Vec3
+	transforms   []mgl.Mat4
+}
+
+// NewModel uploads the meshes and images of a loaded glTF model and builds
+// its default scene, drawn with shader.
+func NewModel(glctx gl.Context, shader loader.Shader, gltf *loader.GLTF) (*Model, error) {
+	if err := gltf.ValidateNodes(); err != nil {
+		return nil, err
+	}
+	model := &Model{
+		Root:         NewNode(nil, nil),
+		Nodes:        make([]*Node, len(gltf.Nodes)),
+		GLTF:         gltf,
+		translations: make([]mgl.Vec3, len(gltf.Nodes)),
+		rotations:    make([]mgl.Quat, len(gltf.Nodes)),
+		scales:       make([]mgl.Vec3, len(gltf.Nodes)),
+		transforms:   make([]mgl.Mat4, len(gltf.Nodes)),
+	}
+
+	for _, t := range gltf.Textures {
+		tex := gl.Texture{}
+		if t.Image >= 0 && t.Image < len(gltf.Images) {
+			tex = loader.NewTexture2D(glctx, gltf.Images[t.Image])
+			params := []struct {
+				name  gl.Enum
+				value int
+			}{
+				{gl.TEXTURE_MAG_FILTER, t.MagFilter},
+				{gl.TEXTURE_MIN_FILTER, t.MinFilter},
+				{gl.TEXTURE_WRAP_S, t.WrapS},
+				{gl.TEXTURE_WRAP_T, t.WrapT},
+			}
+			for _, p := range params {
+				if p.value != 0 {
+					glctx.TexParameteri(gl.TEXTURE_2D, p.name, p.value)
+				}
+			}
+			// Mipmap filters sample an incomplete texture without the levels.
+			switch t.MinFilter {
+			case gl.NEAREST_MIPMAP_NEAREST, gl.LINEAR_MIPMAP_NEAREST, gl.NEAREST_MIPMAP_LINEAR, gl.LINEAR_MIPMAP_LINEAR:
+				glctx.GenerateMipmap(gl.TEXTURE_2D)
+			}
+		}
+		model.Textures = append(model.Textures, tex)
+	}
+
+	// Primitives are built once per mesh and shared between the nodes which
+	// instance it.
+	meshes := make([][]*StaticShape, len(gltf.Meshes))
+	var white gl.Texture // Created for the first primitive without a texture.
+	for i, m := range gltf.Meshes {
+		for j, p := range m.Primitives {
+			shape, err := NewMeshShape(glctx, Mesh{
+				Positions: p.Positions,
+				TexCoords: p.TexCoords,
+				Normals:   p.Normals,
+				Colors:    p.Colors,
+				Joints:    p.Joints,
+				Weights:   p.Weights,
+				Indices:   p.Indices,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("gltf mesh %d primitive %d: %v", i, j, err)
+			}
+			if p.Material >= 0 && p.Material < len(gltf.Materials) {
+				if t := gltf.Materials[p.Material].BaseColorTexture; t >= 0 && t < len(model.Textures) {
+					shape.Texture = model.Textures[t]
+				}
+			}
+			if shape.Texture.Value == 0 {
+				if white.Value == 0 {
+					white = newWhiteTexture(glctx)
+				}
+				shape.Texture = white
+			}
+			meshes[i] = append(meshes[i], shape)
+		}
+	}
+
+	for i, n := range gltf.Nodes {
+		model.translations[i], model.rotations[i], model.scales[i] = n.Translation, n.Rotation, n.Scale
+		model.transforms[i] = n.Transform()
+
+		node := NewNode(nil, nil)
+		node.SetTransform(&model.transforms[i])
+		if n.Mesh >= 0 && n.Mesh < len(meshes) {
+			for j, shape := range meshes[n.Mesh] {
+				var material *loader.PBRMaterial
+				if m := gltf.Meshes[n.Mesh].Primitives[j].Material; m >= 0 && m < len(gltf.Materials) {
+					material = gltf.Materials[m]
+				}
+				child := &materialNode{NewNode(shape, shader), material}
+				child.SetTransparent(material != nil && material.AlphaMode == "BLEND")
+				node.Add(child)
+			}
+		}
+		model.Nodes[i] = node
+	}
+	for i, n := range gltf.Nodes {
+		for _, c := range n.Children {
+			model.Nodes[i].Add(model.Nodes[c])
+		}
+	}
+	for _, r := range gltf.Roots {
+		model.Root.Add(model.Nodes[r])
+	}
+	return model, nil
+}
+
+// Animate poses the model at time t in seconds of the i'th animation. Times
+// outside of the animation are clamped; use GLTFAnimation.Duration to loop.
+func (model *Model) Animate(i int, t float32) {
+	for _, c := range model.GLTF.Animations[i].Channels {
+		if c.Node < 0 || c.Node >= len(model.Nodes) {
+			continue
+		}
+		v := c.Sample(t)
+		switch c.Path {
+		case "translation":
+			model.translations[c.Node] = mgl.Vec3{v[0], v[1], v[2]}
+		case "rotation":
+			model.rotations[c.Node] = mgl.Quat{W: v[3], V: mgl.Vec3{v[0], v[1], v[2]}}
+		case "scale":
+			model.scales[c.Node] = mgl.Vec3{v[0], v[1], v[2]}
+		default:
+			// Morph target weights are not supported.
+			continue
+		}
+		model.transforms[c.Node] = loader.TRS(model.translations[c.Node], model.rotations[c.Node], model.scales[c.Node])
+	}
+}
+
+// JointMatrices returns the skinning matrices of the i'th skin for the
+// current pose, relative to the node of the skinned mesh, for a shader to
+// blend by the vertJoints and vertWeights attributes.
+func (model *Model) JointMatrices(i int, mesh *Node) []mgl.Mat4 {
+	skin := model.GLTF.Skins[i]
+	inv := mesh.WorldTransform().Inv()
+	r := make([]mgl.Mat4, len(skin.Joints))
+	for j, joint := range skin.Joints {
+		r[j] = inv.Mul4(model.Nodes[joint].WorldTransform()).Mul4(skin.InverseBindMatrices[j])
+	}
+	return r
+}
+
+// defaultMaterial is the material of primitives without one.
+var defaultMaterial = loader.PBRMaterial{
+	BaseColor:                mgl.Vec4{1, 1, 1, 1},
+	BaseColorTexture:         -1,
+	Metallic:                 1,
+	Roughness:                1,
+	MetallicRoughnessTexture: -1,
+	NormalTexture:            -1,
+	OcclusionTexture:         -1,
+	EmissiveTexture:          -1,
+	AlphaMode:                "OPAQUE",
+	AlphaCutoff:              0.5,
+}
+
+// newWhiteTexture returns a 1x1 white texture, which leaves the base color
+// unchanged when sampled.
+func newWhiteTexture(glctx gl.Context) gl.Texture {
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	copy(img.Pix, []uint8{255, 255, 255, 255})
+	return loader.NewTexture2D(glctx, img)
+}
+
+// materialNode is a Node which uploads its PBR material before drawing.
+type materialNode struct {
+	*Node
+	material *loader.PBRMaterial
+}
+
+func (node *materialNode) Draw(ctx DrawContext) {
+	m := node.material
+	if m == nil {
+		m = &defaultMaterial
+	}
+	shader := ctx.Shader
+	shader.SetVec4("material.baseColor", m.BaseColor)
+	shader.SetFloat("material.metallic", m.Metallic)
+	shader.SetFloat("material.roughness", m.Roughness)
+	shader.SetVec3("material.emissive", m.Emissive)
+	node.Node.Draw(ctx)
+}
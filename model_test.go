@@ -0,0 +1,134 @@
+package gameblocks
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/shazow/go-gameblocks/camera"
+	"github.com/shazow/go-gameblocks/gltest"
+	"github.com/shazow/go-gameblocks/loader"
+	"golang.org/x/mobile/gl"
+)
+
+func TestNewModel(t *testing.T) {
+	glctx := gltest.NewContext()
+	shader := newTestShader(t, glctx)
+
+	gltf := &loader.GLTF{
+		Roots: []int{0},
+		Nodes: []*loader.GLTFNode{
+			{Children: []int{1}, Mesh: -1, Skin: -1, Rotation: mgl.QuatIdent(), Scale: mgl.Vec3{1, 1, 1}},
+			{Mesh: 0, Skin: -1, Rotation: mgl.QuatIdent(), Scale: mgl.Vec3{1, 1, 1}},
+		},
+		Meshes: []*loader.GLTFMesh{{Primitives: []*loader.GLTFPrimitive{{
+			Positions: []float32{0, 0, 0, 1, 0, 0, 0, 1, 0},
+			Indices:   []uint32{0, 1, 2},
+			Material:  -1,
+		}}}},
+		Animations: []*loader.GLTFAnimation{{Channels: []*loader.GLTFChannel{{
+			Node:          1,
+			Path:          "translation",
+			Interpolation: "LINEAR",
+			Times:         []float32{0, 1},
+			Values:        []float32{0, 0, 0, 0, 2, 0},
+		}}}},
+	}
+	model, err := NewModel(glctx, shader, gltf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := model.Root.Children(); len(got) != 1 || got[0] != model.Nodes[0] {
+		t.Fatalf("got root children %v; want node 0", got)
+	}
+	if model.Nodes[1].Parent() != model.Nodes[0] {
+		t.Errorf("node 1 is not a child of node 0")
+	}
+	if got := model.Nodes[1].Children(); len(got) != 1 || got[0].Shader() != shader {
+		t.Errorf("got mesh children %v; want one primitive", got)
+	}
+
+	model.Animate(0, 0.5)
+	want := mgl.Translate3D(0, 1, 0)
+	if got := model.Nodes[1].WorldTransform(); !got.ApproxEqualThreshold(want, 1e-4) {
+		t.Errorf("got transform %v; want %v", got, want)
+	}
+}
+
+func TestNewModelDefaultMaterial(t *testing.T) {
+	glctx := gltest.NewContext()
+	shader, err := loader.NewShaderSource(glctx, testVertexShader+`
+struct Material {
+	vec4 baseColor;
+	float metallic;
+	float roughness;
+	vec3 emissive;
+};
+uniform Material material;
+`, "void main() {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gltf := &loader.GLTF{
+		Roots: []int{0},
+		Nodes: []*loader.GLTFNode{{Mesh: 0, Skin: -1, Rotation: mgl.QuatIdent(), Scale: mgl.Vec3{1, 1, 1}}},
+		Meshes: []*loader.GLTFMesh{{Primitives: []*loader.GLTFPrimitive{{
+			Positions: []float32{0, 0, 0, 1, 0, 0, 0, 1, 0},
+			Material:  -1,
+		}}}},
+	}
+	model, err := NewModel(glctx, shader, gltf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scene := NewScene()
+	scene.Add(model.Root)
+	glctx.Reset()
+	scene.Draw(FrameContext{GL: glctx, Camera: camera.FixedCamera{}})
+	baseColor := glctx.Filter("Uniform4fv")
+	if len(baseColor) != 1 || !reflect.DeepEqual(baseColor[0].Args[1], []float32{1, 1, 1, 1}) {
+		t.Errorf("got base color uploads %v; want white", baseColor)
+	}
+	floats := glctx.Filter("Uniform1f")
+	if len(floats) != 2 || floats[0].Args[1] != float32(1) || floats[1].Args[1] != float32(1) {
+		t.Errorf("got float uploads %v; want metallic and roughness of 1", floats)
+	}
+	binds := glctx.Filter("BindTexture")
+	if len(binds) != 1 || binds[0].Args[1] == (gl.Texture{}) {
+		t.Errorf("got texture binds %v; want the white texture", binds)
+	}
+}
+
+func TestNewModelMipmaps(t *testing.T) {
+	glctx := gltest.NewContext()
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	gltf := &loader.GLTF{
+		Images: []image.Image{img, img},
+		Textures: []*loader.GLTFTexture{
+			{Image: 0, MinFilter: gl.LINEAR},
+			{Image: 1, MinFilter: gl.LINEAR_MIPMAP_LINEAR},
+		},
+	}
+	if _, err := NewModel(glctx, newTestShader(t, glctx), gltf); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(glctx.Filter("GenerateMipmap")); got != 1 {
+		t.Errorf("got %d mipmaps generated; want 1 for the mipmap filter", got)
+	}
+}
+
+func TestNewModelCycle(t *testing.T) {
+	glctx := gltest.NewContext()
+	gltf := &loader.GLTF{
+		Nodes: []*loader.GLTFNode{
+			{Children: []int{1}, Mesh: -1, Skin: -1},
+			{Children: []int{0}, Mesh: -1, Skin: -1},
+		},
+	}
+	if _, err := NewModel(glctx, newTestShader(t, glctx), gltf); err == nil {
+		t.Error("expected error for a node cycle")
+	}
+}
@@ -0,0 +1,218 @@
+package gameblocks
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+
+	"golang.org/x/mobile/event/size"
+	"golang.org/x/mobile/exp/gl/glutil"
+	"golang.org/x/mobile/geom"
+)
+
+// Text overlay limits, in characters.
+const (
+	overlayCols  = 120
+	overlayLines = 24
+	overlayScale = 2 // Screen pixels per font pixel
+)
+
+var (
+	overlayBackground = color.RGBA{0x60, 0, 0, 0xff}
+	overlayForeground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+)
+
+// errorOverlay draws the text of an error over the top of the frame, such as
+// a shader which failed to compile while developing.
+type errorOverlay struct {
+	images *glutil.Images
+	image  *glutil.Image
+	text   string
+}
+
+// SetError replaces the error shown, or hides the overlay if err is nil.
+func (o *errorOverlay) SetError(err error) {
+	text := ""
+	if err != nil {
+		text = err.Error()
+	}
+	if text == o.text {
+		return
+	}
+	o.text = text
+	o.Release()
+}
+
+func (o *errorOverlay) Draw(sz size.Event) {
+	if o.text == "" || sz.PixelsPerPt == 0 {
+		return
+	}
+	if o.image == nil {
+		src := renderText(o.text, overlayCols, overlayLines, overlayScale)
+		b := src.Bounds()
+		o.image = o.images.NewImage(b.Dx(), b.Dy())
+		draw.Draw(o.image.RGBA, b, src, image.Point{}, draw.Src)
+		o.image.Upload()
+	}
+
+	b := o.image.RGBA.Bounds()
+	w := geom.Pt(float32(b.Dx()) / sz.PixelsPerPt)
+	h := geom.Pt(float32(b.Dy()) / sz.PixelsPerPt)
+	o.image.Draw(sz, geom.Point{}, geom.Point{X: w}, geom.Point{Y: h}, b)
+}
+
+func (o *errorOverlay) Release() {
+	if o.image != nil {
+		o.image.Release()
+		o.image = nil
+	}
+}
+
+// renderText draws text in a 5x7 pixel font, cut to fit cols by lines
+// characters, with each font pixel scaled to a square of scale pixels.
+func renderText(text string, cols, lines, scale int) *image.RGBA {
+	rows := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(rows) > lines {
+		rows = rows[:lines]
+	}
+	width := 0
+	for i, row := range rows {
+		row = strings.ReplaceAll(row, "\t", "  ")
+		if len(row) > cols {
+			row = row[:cols]
+		}
+		rows[i] = row
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	// Glyphs are 5x7 in a 6x9 cell, with a cell of margin around the text.
+	const cellW, cellH = 6, 9
+	img := image.NewRGBA(image.Rect(0, 0, (width+2)*cellW*scale, (len(rows)+2)*cellH*scale))
+	draw.Draw(img, img.Bounds(), image.NewUniform(overlayBackground), image.Point{}, draw.Src)
+	for line, row := range rows {
+		for col := 0; col < len(row); col++ {
+			ch := row[col]
+			if ch < ' ' || ch > '~' {
+				ch = '?'
+			}
+			glyph := font5x7[ch-' ']
+			x0, y0 := (col+1)*cellW*scale, (line+1)*cellH*scale
+			for gx, bits := range glyph {
+				for gy := 0; gy < 7; gy++ {
+					if bits&(1<<gy) == 0 {
+						continue
+					}
+					for dy := 0; dy < scale; dy++ {
+						for dx := 0; dx < scale; dx++ {
+							img.SetRGBA(x0+gx*scale+dx, y0+gy*scale+dy, overlayForeground)
+						}
+					}
+				}
+			}
+		}
+	}
+	return img
+}
+
+// font5x7 is a 5x7 pixel font for printable ASCII, from ' ' to '~'. Each
+// glyph is five columns, with the top row in the lowest bit.
+var font5x7 = [95][5]byte{
+	{0x00, 0x00, 0x00, 0x00, 0x00}, // ' '
+	{0x00, 0x00, 0x5f, 0x00, 0x00}, // !
+	{0x00, 0x07, 0x00, 0x07, 0x00}, // "
+	{0x14, 0x7f, 0x14, 0x7f, 0x14}, // #
+	{0x24, 0x2a, 0x7f, 0x2a, 0x12}, // $
+	{0x23, 0x13, 0x08, 0x64, 0x62}, // %
+	{0x36, 0x49, 0x55, 0x22, 0x50}, // &
+	{0x00, 0x05, 0x03, 0x00, 0x00}, // '
+	{0x00, 0x1c, 0x22, 0x41, 0x00}, // (
+	{0x00, 0x41, 0x22, 0x1c, 0x00}, // )
+	{0x14, 0x08, 0x3e, 0x08, 0x14}, // *
+	{0x08, 0x08, 0x3e, 0x08, 0x08}, // +
+	{0x00, 0x50, 0x30, 0x00, 0x00}, // ,
+	{0x08, 0x08, 0x08, 0x08, 0x08}, // -
+	{0x00, 0x60, 0x60, 0x00, 0x00}, // .
+	{0x20, 0x10, 0x08, 0x04, 0x02}, // /
+	{0x3e, 0x51, 0x49, 0x45, 0x3e}, // 0
+	{0x00, 0x42, 0x7f, 0x40, 0x00}, // 1
+	{0x42, 0x61, 0x51, 0x49, 0x46}, // 2
+	{0x21, 0x41, 0x45, 0x4b, 0x31}, // 3
+	{0x18, 0x14, 0x12, 0x7f, 0x10}, // 4
+	{0x27, 0x45, 0x45, 0x45, 0x39}, // 5
+	{0x3c, 0x4a, 0x49, 0x49, 0x30}, // 6
+	{0x01, 0x71, 0x09, 0x05, 0x03}, // 7
+	{0x36, 0x49, 0x49, 0x49, 0x36}, // 8
+	{0x06, 0x49, 0x49, 0x29, 0x1e}, // 9
+	{0x00, 0x36, 0x36, 0x00, 0x00}, // :
+	{0x00, 0x56, 0x36, 0x00, 0x00}, // ;
+	{0x08, 0x14, 0x22, 0x41, 0x00}, // <
+	{0x14, 0x14, 0x14, 0x14, 0x14}, // =
+	{0x00, 0x41, 0x22, 0x14, 0x08}, // >
+	{0x02, 0x01, 0x51, 0x09, 0x06}, // ?
+	{0x32, 0x49, 0x79, 0x41, 0x3e}, // @
+	{0x7e, 0x11, 0x11, 0x11, 0x7e}, // A
+	{0x7f, 0x49, 0x49, 0x49, 0x36}, // B
+	{0x3e, 0x41, 0x41, 0x41, 0x22}, // C
+	{0x7f, 0x41, 0x41, 0x22, 0x1c}, // D
+	{0x7f, 0x49, 0x49, 0x49, 0x41}, // E
+	{0x7f, 0x09, 0x09, 0x09, 0x01}, // F
+	{0x3e, 0x41, 0x49, 0x49, 0x7a}, // G
+	{0x7f, 0x08, 0x08, 0x08, 0x7f}, // H
+	{0x00, 0x41, 0x7f, 0x41, 0x00}, // I
+	{0x20, 0x40, 0x41, 0x3f, 0x01}, // J
+	{0x7f, 0x08, 0x14, 0x22, 0x41}, // K
+	{0x7f, 0x40, 0x40, 0x40, 0x40}, // L
+	{0x7f, 0x02, 0x0c, 0x02, 0x7f}, // M
+	{0x7f, 0x04, 0x08, 0x10, 0x7f}, // N
+	{0x3e, 0x41, 0x41, 0x41, 0x3e}, // O
+	{0x7f, 0x09, 0x09, 0x09, 0x06}, // P
+	{0x3e, 0x41, 0x51, 0x21, 0x5e}, // Q
+	{0x7f, 0x09, 0x19, 0x29, 0x46}, // R
+	{0x46, 0x49, 0x49, 0x49, 0x31}, // S
+	{0x01, 0x01, 0x7f, 0x01, 0x01}, // T
+	{0x3f, 0x40, 0x40, 0x40, 0x3f}, // U
+	{0x1f, 0x20, 0x40, 0x20, 0x1f}, // V
+	{0x3f, 0x40, 0x38, 0x40, 0x3f}, // W
+	{0x63, 0x14, 0x08, 0x14, 0x63}, // X
+	{0x07, 0x08, 0x70, 0x08, 0x07}, // Y
+	{0x61, 0x51, 0x49, 0x45, 0x43}, // Z
+	{0x00, 0x7f, 0x41, 0x41, 0x00}, // [
+	{0x02, 0x04, 0x08, 0x10, 0x20}, // \
+	{0x00, 0x41, 0x41, 0x7f, 0x00}, // ]
+	{0x04, 0x02, 0x01, 0x02, 0x04}, // ^
+	{0x40, 0x40, 0x40, 0x40, 0x40}, // _
+	{0x00, 0x01, 0x02, 0x04, 0x00}, // `
+	{0x20, 0x54, 0x54, 0x54, 0x78}, // a
+	{0x7f, 0x48, 0x44, 0x44, 0x38}, // b
+	{0x38, 0x44, 0x44, 0x44, 0x20}, // c
+	{0x38, 0x44, 0x44, 0x48, 0x7f}, // d
+	{0x38, 0x54, 0x54, 0x54, 0x18}, // e
+	{0x08, 0x7e, 0x09, 0x01, 0x02}, // f
+	{0x0c, 0x52, 0x52, 0x52, 0x3e}, // g
+	{0x7f, 0x08, 0x04, 0x04, 0x78}, // h
+	{0x00, 0x44, 0x7d, 0x40, 0x00}, // i
+	{0x20, 0x40, 0x44, 0x3d, 0x00}, // j
+	{0x7f, 0x10, 0x28, 0x44, 0x00}, // k
+	{0x00, 0x41, 0x7f, 0x40, 0x00}, // l
+	{0x7c, 0x04, 0x18, 0x04, 0x78}, // m
+	{0x7c, 0x08, 0x04, 0x04, 0x78}, // n
+	{0x38, 0x44, 0x44, 0x44, 0x38}, // o
+	{0x7c, 0x14, 0x14, 0x14, 0x08}, // p
+	{0x08, 0x14, 0x14, 0x18, 0x7c}, // q
+	{0x7c, 0x08, 0x04, 0x04, 0x08}, // r
+	{0x48, 0x54, 0x54, 0x54, 0x20}, // s
+	{0x04, 0x3f, 0x44, 0x40, 0x20}, // t
+	{0x3c, 0x40, 0x40, 0x20, 0x7c}, // u
+	{0x1c, 0x20, 0x40, 0x20, 0x1c}, // v
+	{0x3c, 0x40, 0x30, 0x40, 0x3c}, // w
+	{0x44, 0x28, 0x10, 0x28, 0x44}, // x
+	{0x0c, 0x50, 0x50, 0x50, 0x3c}, // y
+	{0x44, 0x64, 0x54, 0x4c, 0x44}, // z
+	{0x00, 0x08, 0x36, 0x41, 0x00}, // {
+	{0x00, 0x00, 0x7f, 0x00, 0x00}, // |
+	{0x00, 0x41, 0x36, 0x08, 0x00}, // }
+	{0x10, 0x08, 0x08, 0x10, 0x08}, // ~
+}
@@ -0,0 +1,26 @@
+package gameblocks
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestRenderText(t *testing.T) {
+	img := renderText("Hi\nthere\n", 80, 10, 1)
+	if got, want := img.Bounds(), image.Rect(0, 0, 7*6, 4*9); got != want {
+		t.Fatalf("got bounds %v; want %v", got, want)
+	}
+	// The left edge of H, after a cell of margin.
+	if got := img.RGBAAt(6, 9); got != overlayForeground {
+		t.Errorf("got %v at the top of H; want the foreground", got)
+	}
+	if got := img.RGBAAt(0, 0); got != overlayBackground {
+		t.Errorf("got %v in the margin; want the background", got)
+	}
+
+	long := renderText(strings.Repeat("x", 200)+strings.Repeat("\nline", 50), 100, 20, 2)
+	if got, want := long.Bounds(), image.Rect(0, 0, 102*6*2, 22*9*2); got != want {
+		t.Errorf("got bounds %v for long text; want %v", got, want)
+	}
+}
@@ -1,8 +1,6 @@
 package gameblocks
 
 import (
-	"bytes"
-	"encoding/binary"
 	"math/rand"
 	"time"
 
@@ -38,9 +36,11 @@ type particle struct {
 	velocity mgl.Vec3
 }
 
-func (p *particle) Vertices() []float32 {
+// Vertices returns the triangle for the particle, as an array to keep it off
+// the heap.
+func (p *particle) Vertices() [9]float32 {
 	v := p.velocity.Normalize()
-	return []float32{
+	return [9]float32{
 		p.position[0] + v[0]*0.1,
 		p.position[1] + v[1]*0.1,
 		p.position[2] + v[2]*0.1,
@@ -58,6 +58,8 @@ func (p *particle) Tick(force mgl.Vec3) {
 	p.position = p.position.Add(p.velocity)
 }
 
+var particleLayout = NewVertexLayout(VertexAttrib{Name: "vertCoord", Type: gl.FLOAT, Dim: vertexDim})
+
 var particleForce float32 = 0.09
 var gravityForce = mgl.Vec3{0, -0.2, 0}
 
@@ -85,6 +87,8 @@ type particleEmitter struct {
 	rate      float32
 	particles []*particle
 	num       int
+
+	enc Encoder
 }
 
 func (emitter *particleEmitter) MoveTo(pos mgl.Vec3) {
@@ -127,16 +131,18 @@ func (emitter *particleEmitter) Len() int {
 	return len(emitter.particles)
 }
 
+// Bytes encodes the particles into a buffer which is reused by the next call.
 func (emitter *particleEmitter) Bytes() []byte {
-	buf := bytes.Buffer{}
+	emitter.enc.Reset(len(emitter.particles) * 9 * vecSize)
 	for _, particle := range emitter.particles {
-		binary.Write(&buf, binary.LittleEndian, particle.Vertices())
+		v := particle.Vertices()
+		emitter.enc.Float32s(v[:])
 	}
-	return buf.Bytes()
+	return emitter.enc.Bytes()
 }
 
 func (emitter *particleEmitter) Stride() int {
-	return vecSize * vertexDim
+	return particleLayout.Stride
 }
 
 func (emitter *particleEmitter) Draw(ctx DrawContext) {
@@ -144,12 +150,9 @@ func (emitter *particleEmitter) Draw(ctx DrawContext) {
 	glctx := ctx.GL
 	glctx.BindBuffer(gl.ARRAY_BUFFER, emitter.VBO)
 
-	glctx.EnableVertexAttribArray(shader.Attrib("vertCoord"))
-	glctx.VertexAttribPointer(shader.Attrib("vertCoord"), vertexDim, gl.FLOAT, false, emitter.Stride(), 0)
-
+	particleLayout.Enable(glctx, shader)
 	glctx.DrawArrays(gl.TRIANGLES, 0, emitter.Len()*particleLen/vertexDim)
-
-	glctx.DisableVertexAttribArray(shader.Attrib("vertCoord"))
+	particleLayout.Disable(glctx, shader)
 }
 
 func (emitter *particleEmitter) Close() error {
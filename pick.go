@@ -0,0 +1,207 @@
+package gameblocks
+
+import (
+	"math"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/shazow/go-gameblocks/camera"
+)
+
+// Ray is a half-line from Origin along Direction, which is normalized.
+type Ray struct {
+	Origin, Direction mgl.Vec3
+}
+
+// NewRay returns the ray from cam through a point on screen, in pixels from
+// the top left of a width by height surface. The ray starts on the near plane.
+func NewRay(cam camera.Camera, x, y, width, height float32) Ray {
+	inv := cam.Projection().Mul4(cam.View()).Inv()
+	ndcX, ndcY := 2*x/width-1, 1-2*y/height
+	near := inv.Mul4x1(mgl.Vec4{ndcX, ndcY, -1, 1})
+	far := inv.Mul4x1(mgl.Vec4{ndcX, ndcY, 1, 1})
+	origin := near.Vec3().Mul(1 / near[3])
+	return Ray{
+		Origin:    origin,
+		Direction: far.Vec3().Mul(1 / far[3]).Sub(origin).Normalize(),
+	}
+}
+
+// At returns the point at distance t along the ray.
+func (r Ray) At(t float32) mgl.Vec3 {
+	return r.Origin.Add(r.Direction.Mul(t))
+}
+
+// Transform returns the ray in the space of m. The direction is normalized
+// again, so distances along the result are in the new space.
+func (r Ray) Transform(m mgl.Mat4) Ray {
+	return Ray{
+		Origin:    m.Mul4x1(r.Origin.Vec4(1)).Vec3(),
+		Direction: m.Mul4x1(r.Direction.Vec4(0)).Vec3().Normalize(),
+	}
+}
+
+// IntersectAABB returns the distance to where the ray enters the box, or zero
+// if it starts inside.
+func (r Ray) IntersectAABB(b AABB) (float32, bool) {
+	tmin, tmax := float32(0), float32(math.Inf(1))
+	for i := 0; i < 3; i++ {
+		if r.Direction[i] == 0 {
+			if r.Origin[i] < b.Min[i] || r.Origin[i] > b.Max[i] {
+				return 0, false
+			}
+			continue
+		}
+		inv := 1 / r.Direction[i]
+		t0, t1 := (b.Min[i]-r.Origin[i])*inv, (b.Max[i]-r.Origin[i])*inv
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		if t0 > tmin {
+			tmin = t0
+		}
+		if t1 < tmax {
+			tmax = t1
+		}
+		if tmin > tmax {
+			return 0, false
+		}
+	}
+	return tmin, true
+}
+
+// IntersectTriangle returns the distance to where the ray crosses the
+// triangle abc, from either side.
+func (r Ray) IntersectTriangle(a, b, c mgl.Vec3) (float32, bool) {
+	// Möller–Trumbore
+	const epsilon = 1e-7
+	ab, ac := b.Sub(a), c.Sub(a)
+	p := r.Direction.Cross(ac)
+	det := ab.Dot(p)
+	if det > -epsilon && det < epsilon {
+		return 0, false
+	}
+	inv := 1 / det
+	s := r.Origin.Sub(a)
+	u := s.Dot(p) * inv
+	if u < 0 || u > 1 {
+		return 0, false
+	}
+	q := s.Cross(ab)
+	v := r.Direction.Dot(q) * inv
+	if v < 0 || u+v > 1 {
+		return 0, false
+	}
+	t := ac.Dot(q) * inv
+	return t, t >= 0
+}
+
+// Hit is the result of a successful Pick.
+type Hit struct {
+	Drawable Drawable
+	Distance float32  // From the ray origin, in world units
+	Point    mgl.Vec3 // In world space
+}
+
+// intersecter is implemented by Drawables which can be hit tested against
+// their triangles, given a ray in local space.
+type intersecter interface {
+	Intersect(ray Ray) (float32, bool)
+}
+
+// Intersect returns the distance to the nearest triangle of the Shape hit by
+// a ray in the node's local space, if the Shape is a StaticShape.
+func (node *Node) Intersect(ray Ray) (float32, bool) {
+	shape, ok := node.Shape.(*StaticShape)
+	if !ok {
+		return 0, false
+	}
+	return shape.Intersect(ray)
+}
+
+// Intersect returns the distance to the nearest triangle hit by the ray,
+// testing every triangle. Distances are in the shape's space.
+func (shape *StaticShape) Intersect(ray Ray) (float32, bool) {
+	n := shape.Len()
+	if shape.numIndices > 0 {
+		n = shape.numIndices
+	}
+	vertex := func(i int) mgl.Vec3 {
+		if shape.numIndices > 0 {
+			i = shape.index(i)
+		}
+		v := shape.vertices[i*vertexDim:]
+		return mgl.Vec3{v[0], v[1], v[2]}
+	}
+
+	nearest, hit := float32(math.Inf(1)), false
+	for i := 0; i+2 < n; i += 3 {
+		if t, ok := ray.IntersectTriangle(vertex(i), vertex(i+1), vertex(i+2)); ok && t < nearest {
+			nearest, hit = t, true
+		}
+	}
+	return nearest, hit
+}
+
+// Intersect returns the distance to the nearest triangle of any instance,
+// for a ray in the node's local space.
+func (node *InstancedNode) Intersect(ray Ray) (float32, bool) {
+	nearest, hit := float32(math.Inf(1)), false
+	for _, instance := range node.instances {
+		local := ray.Transform(instance.Transform.Inv())
+		t, ok := node.shape.Intersect(local)
+		if !ok {
+			continue
+		}
+		// Measure in the node's space, as instances may be scaled.
+		point := instance.Transform.Mul4x1(local.At(t).Vec4(1)).Vec3()
+		if d := point.Sub(ray.Origin).Len(); d < nearest {
+			nearest, hit = d, true
+		}
+	}
+	return nearest, hit
+}
+
+// pick tests item and its descendants against a world space ray, keeping the
+// nearest hit in nearest.
+func pick(item Drawable, parent *mgl.Mat4, ray Ray, exact bool, nearest *Hit) {
+	transform := item.Transform(parent)
+	if hit, ok := hitTest(item, transform, ray, exact); ok {
+		if nearest.Drawable == nil || hit.Distance < nearest.Distance {
+			*nearest = hit
+		}
+	}
+	if container, ok := item.(Container); ok {
+		for _, child := range container.Children() {
+			pick(child, &transform, ray, exact, nearest)
+		}
+	}
+}
+
+// hitTest intersects the ray with the world bounds of item and, if exact, its
+// triangles. Items which are not drawn or have no bounds are never hit.
+func hitTest(item Drawable, transform mgl.Mat4, ray Ray, exact bool) (Hit, bool) {
+	b, ok := item.(Bounded)
+	if !ok || item.Shader() == nil {
+		return Hit{}, false
+	}
+	local, ok := b.Bounds()
+	if !ok {
+		return Hit{}, false
+	}
+	t, ok := ray.IntersectAABB(local.Transform(transform))
+	if !ok {
+		return Hit{}, false
+	}
+	i, ok := item.(intersecter)
+	if !exact || !ok {
+		return Hit{Drawable: item, Distance: t, Point: ray.At(t)}, true
+	}
+
+	localRay := ray.Transform(transform.Inv())
+	lt, ok := i.Intersect(localRay)
+	if !ok {
+		return Hit{}, false
+	}
+	point := transform.Mul4x1(localRay.At(lt).Vec4(1)).Vec3()
+	return Hit{Drawable: item, Distance: point.Sub(ray.Origin).Len(), Point: point}, true
+}
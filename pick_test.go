@@ -0,0 +1,51 @@
+package gameblocks
+
+import (
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/shazow/go-gameblocks/camera"
+	"github.com/shazow/go-gameblocks/gltest"
+)
+
+func TestScenePick(t *testing.T) {
+	glctx := gltest.NewContext()
+	shader := newTestShader(t, glctx)
+	shape := NewStaticShape(glctx)
+	shape.vertices = []float32{-1, -1, 0, 1, -1, 0, 0, 1, 0}
+	shape.setIndices([]uint32{0, 1, 2})
+	shape.Buffer()
+
+	scene := NewScene()
+	var nodes []*Node
+	for _, z := range []float32{-20, -10} {
+		node := NewNode(shape, shader)
+		transform := mgl.Translate3D(0, 0, z)
+		node.SetTransform(&transform)
+		scene.Add(node)
+		nodes = append(nodes, node)
+	}
+
+	cam := camera.NewQuatCamera()
+	cam.SetPerspective(mgl.DegToRad(60), 1, 0.1, 100)
+
+	hit, ok := scene.Pick(NewRay(cam, 50, 50, 100, 100), true)
+	if !ok {
+		t.Fatal("missed the center of the screen")
+	}
+	if hit.Drawable != nodes[1] {
+		t.Errorf("got %v; want the nearest node", hit.Drawable)
+	}
+	if want := (mgl.Vec3{0, 0, -10}); !hit.Point.ApproxEqualThreshold(want, 1e-3) || mgl.Abs(hit.Distance-9.9) > 1e-3 {
+		t.Errorf("got hit at %v, distance %v; want %v", hit.Point, hit.Distance, want)
+	}
+
+	// Towards the top left corner of the bounds, outside the triangle.
+	ray := NewRay(cam, 42.2, 42.2, 100, 100)
+	if _, ok := scene.Pick(ray, false); !ok {
+		t.Error("missed the bounds")
+	}
+	if hit, ok := scene.Pick(ray, true); ok {
+		t.Errorf("got exact hit %v; want none", hit)
+	}
+}
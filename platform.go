@@ -0,0 +1,44 @@
+package gameblocks
+
+import "github.com/shazow/go-gameblocks/event"
+
+// Platform is a backend which owns the window, the GL context and the event
+// loop, such as MobilePlatform or DesktopPlatform.
+type Platform interface {
+	// Run blocks, calling handle with each event from the event package until
+	// the platform exits or handle returns an error. A frame is presented
+	// after handle returns for an event.Paint.
+	Run(handle func(e interface{}) error) error
+}
+
+// Start drives the Engine with events from the Platform, should be run from
+// the main thread.
+func Start(platform Platform, engine Engine) error {
+	started := false
+	return platform.Run(func(e interface{}) error {
+		switch e := e.(type) {
+		case event.Lifecycle:
+			switch {
+			case e.Stage == event.StageVisible && !started:
+				if err := engine.Start(e.GL); err != nil {
+					return err
+				}
+				started = true
+			case e.Stage == event.StageHidden && started:
+				engine.Stop()
+				started = false
+			}
+		case event.Paint:
+			if started {
+				engine.Draw()
+			}
+		case event.Resize:
+			engine.Resize(e)
+		case event.Pointer:
+			engine.Touch(e)
+		case event.Key:
+			engine.Press(e)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,60 @@
+package gameblocks
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shazow/go-gameblocks/event"
+	"github.com/shazow/go-gameblocks/gltest"
+	"golang.org/x/mobile/gl"
+)
+
+type fakePlatform []interface{}
+
+func (p fakePlatform) Run(handle func(e interface{}) error) error {
+	for _, e := range p {
+		if err := handle(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type recordingEngine struct {
+	calls []string
+}
+
+func (e *recordingEngine) Draw() { e.calls = append(e.calls, "Draw") }
+func (e *recordingEngine) Start(glctx gl.Context) error {
+	e.calls = append(e.calls, "Start")
+	return nil
+}
+func (e *recordingEngine) Stop()                  { e.calls = append(e.calls, "Stop") }
+func (e *recordingEngine) Touch(t event.Pointer)  { e.calls = append(e.calls, "Touch") }
+func (e *recordingEngine) Press(t event.Key)      { e.calls = append(e.calls, "Press") }
+func (e *recordingEngine) Resize(sz event.Resize) { e.calls = append(e.calls, "Resize") }
+func (e *recordingEngine) Pick(x, y float32, exact bool) (Hit, bool) {
+	return Hit{}, false
+}
+
+func TestStart(t *testing.T) {
+	platform := fakePlatform{
+		event.Paint{}, // Dropped before the surface is visible.
+		event.Lifecycle{Stage: event.StageVisible, GL: gltest.NewContext()},
+		event.Resize{WidthPx: 640, HeightPx: 480, PixelsPerPt: 1},
+		event.Pointer{Type: event.PointerDown},
+		event.Key{Code: event.CodeW, Direction: event.DirPress},
+		event.Paint{},
+		event.Lifecycle{Stage: event.StageHidden},
+		event.Paint{},
+	}
+	engine := &recordingEngine{}
+	if err := Start(platform, engine); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Start", "Resize", "Touch", "Press", "Draw", "Stop"}
+	if !reflect.DeepEqual(engine.calls, want) {
+		t.Errorf("got %v; want %v", engine.calls, want)
+	}
+}
@@ -0,0 +1,189 @@
+package gameblocks
+
+import (
+	"sort"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/shazow/go-gameblocks/loader"
+	"golang.org/x/mobile/gl"
+)
+
+// Transparent is implemented by Drawables which may need blending, such as a
+// Node after SetTransparent. Transparent items are drawn after opaque ones.
+type Transparent interface {
+	Transparent() bool
+}
+
+// Bounded is implemented by Drawables with a bounding box in local space,
+// such as a Node with a StaticShape, which lets them be culled.
+type Bounded interface {
+	Bounds() (AABB, bool)
+}
+
+// RenderStats counts the items considered by a RenderQueue in a frame.
+type RenderStats struct {
+	Drawn  int
+	Culled int
+}
+
+// queueItem is a Drawable waiting in a RenderQueue, with the world transform
+// of its parent.
+type queueItem struct {
+	drawable Drawable
+	parent   mgl.Mat4
+	shader   int    // Order the shader was first seen in
+	texture  uint32 // Texture handle, or zero
+	distance float32
+}
+
+// RenderQueue collects the Drawables of a frame and draws them in an order
+// which reduces state changes: opaque items grouped by shader and then
+// texture, followed by transparent items from back to front with blending
+// enabled. The queue can be reused between frames.
+//
+// If a frustum is set, Bounded items outside of it are skipped.
+type RenderQueue struct {
+	opaque      []queueItem
+	transparent []queueItem
+	shaders     map[loader.Shader]int
+
+	frustum *Frustum
+	stats   RenderStats
+}
+
+// SetFrustum enables culling against f for the following Pushes.
+func (queue *RenderQueue) SetFrustum(f Frustum) {
+	queue.frustum = &f
+}
+
+// Stats returns the counts since the last Reset.
+func (queue *RenderQueue) Stats() RenderStats {
+	return queue.stats
+}
+
+// Reset empties the queue, keeping its storage.
+func (queue *RenderQueue) Reset() {
+	queue.stats = RenderStats{}
+	queue.opaque = queue.opaque[:0]
+	queue.transparent = queue.transparent[:0]
+	for shader := range queue.shaders {
+		delete(queue.shaders, shader)
+	}
+}
+
+// Len returns the number of queued items.
+func (queue *RenderQueue) Len() int {
+	return len(queue.opaque) + len(queue.transparent)
+}
+
+// Push queues item, whose parent has the given world transform, and its
+// descendants if it is a Container. Items without a shader are not drawn but
+// their children are, as are the children of culled items.
+func (queue *RenderQueue) Push(item Drawable, parent *mgl.Mat4) {
+	if shader := item.Shader(); shader != nil && queue.culled(item, parent) {
+		queue.stats.Culled++
+	} else if shader != nil {
+		queue.stats.Drawn++
+		if queue.shaders == nil {
+			queue.shaders = map[loader.Shader]int{}
+		}
+		id, ok := queue.shaders[shader]
+		if !ok {
+			id = len(queue.shaders)
+			queue.shaders[shader] = id
+		}
+		entry := queueItem{
+			drawable: item,
+			parent:   MultiMul(parent),
+			shader:   id,
+			texture:  drawableTexture(item),
+		}
+		if t, ok := item.(Transparent); ok && t.Transparent() {
+			queue.transparent = append(queue.transparent, entry)
+		} else {
+			queue.opaque = append(queue.opaque, entry)
+		}
+	}
+
+	container, ok := item.(Container)
+	if !ok || len(container.Children()) == 0 {
+		return
+	}
+	transform := item.Transform(parent)
+	for _, child := range container.Children() {
+		queue.Push(child, &transform)
+	}
+}
+
+// culled reports whether item is outside of the frustum.
+func (queue *RenderQueue) culled(item Drawable, parent *mgl.Mat4) bool {
+	if queue.frustum == nil {
+		return false
+	}
+	b, ok := item.(Bounded)
+	if !ok {
+		return false
+	}
+	local, ok := b.Bounds()
+	if !ok {
+		return false
+	}
+	world := local.Transform(item.Transform(parent))
+	return !queue.frustum.IntersectsSphere(world.Sphere()) || !queue.frustum.IntersectsAABB(world)
+}
+
+// Draw sorts the queue for the frame's camera and draws it.
+func (queue *RenderQueue) Draw(frame *FrameContext) {
+	sort.SliceStable(queue.opaque, func(i, j int) bool {
+		a, b := queue.opaque[i], queue.opaque[j]
+		if a.shader != b.shader {
+			return a.shader < b.shader
+		}
+		return a.texture < b.texture
+	})
+	for i := range queue.opaque {
+		queue.drawItem(frame, &queue.opaque[i])
+	}
+
+	if len(queue.transparent) == 0 {
+		return
+	}
+	eye := frame.Camera.Position()
+	for i := range queue.transparent {
+		item := &queue.transparent[i]
+		world := item.drawable.Transform(&item.parent)
+		item.distance = world.Col(3).Vec3().Sub(eye).Len()
+	}
+	sort.SliceStable(queue.transparent, func(i, j int) bool {
+		return queue.transparent[i].distance > queue.transparent[j].distance
+	})
+
+	glctx := frame.GL
+	glctx.Enable(gl.BLEND)
+	glctx.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	glctx.DepthMask(false)
+	for i := range queue.transparent {
+		queue.drawItem(frame, &queue.transparent[i])
+	}
+	glctx.DepthMask(true)
+	glctx.Disable(gl.BLEND)
+}
+
+func (queue *RenderQueue) drawItem(frame *FrameContext, item *queueItem) {
+	ctx := frame.DrawContext(item.drawable.Shader())
+	ctx.Transform = &item.parent
+	item.drawable.Draw(ctx)
+}
+
+// drawableTexture returns the texture handle of a Node's StaticShape, for
+// sorting.
+func drawableTexture(item Drawable) uint32 {
+	n, ok := item.(sceneNode)
+	if !ok {
+		return 0
+	}
+	if shape, ok := n.sceneNode().Shape.(*StaticShape); ok {
+		return shape.Texture.Value
+	}
+	return 0
+}
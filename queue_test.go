@@ -0,0 +1,57 @@
+package gameblocks
+
+import (
+	"reflect"
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/shazow/go-gameblocks/camera"
+	"github.com/shazow/go-gameblocks/gltest"
+	"github.com/shazow/go-gameblocks/loader"
+)
+
+// namedNode records its name when drawn.
+type namedNode struct {
+	*Node
+	name  string
+	drawn *[]string
+}
+
+func (node *namedNode) Draw(ctx DrawContext) {
+	*node.drawn = append(*node.drawn, node.name)
+}
+
+func TestRenderQueue(t *testing.T) {
+	glctx := gltest.NewContext()
+	shaders := []loader.Shader{newTestShader(t, glctx), newTestShader(t, glctx)}
+
+	var drawn []string
+	add := func(scene Scene, name string, shader int, z float32, transparent bool) {
+		node := NewNode(nil, shaders[shader])
+		transform := mgl.Translate3D(0, 0, z)
+		node.SetTransform(&transform)
+		node.SetTransparent(transparent)
+		scene.Add(&namedNode{node, name, &drawn})
+	}
+
+	scene := NewScene()
+	add(scene, "near glass", 0, 1, true)
+	add(scene, "a1", 0, 0, false)
+	add(scene, "b1", 1, 0, false)
+	add(scene, "far glass", 1, 5, true)
+	add(scene, "a2", 0, 0, false)
+
+	glctx.Reset()
+	scene.Draw(FrameContext{GL: glctx, Camera: camera.FixedCamera{}})
+
+	want := []string{"a1", "a2", "b1", "far glass", "near glass"}
+	if !reflect.DeepEqual(drawn, want) {
+		t.Errorf("got draw order %v; want %v", drawn, want)
+	}
+	if got := len(glctx.Filter("UseProgram")); got != 3 {
+		t.Errorf("got %d program switches; want 3", got)
+	}
+	if got := glctx.Names(); got[len(got)-1] != "Disable" {
+		t.Errorf("blending not disabled after the transparent pass: %v", got)
+	}
+}
@@ -14,17 +14,42 @@ type FrameContext struct {
 	GL     gl.Context
 	Camera camera.Camera
 
+	// Alpha is how far this frame is between the last World tick and the
+	// next, in [0, 1), for interpolating between simulation states.
+	Alpha float32
+
+	// Lights are uploaded to each shader, up to MaxLights (or
+	// DefaultMaxLights if zero). Set by the Scene being drawn.
+	Lights    []*Light
+	MaxLights int
+
+	// Uniforms, if set, remembers the camera and light uniforms of each
+	// shader across frames so only changes are uploaded. Otherwise they are
+	// uploaded to each shader once per frame.
+	Uniforms *Uniforms
+
 	shaderCache  map[loader.Shader]struct{}
 	activeShader loader.Shader
 }
 
 func (ctx *FrameContext) bindShader(shader loader.Shader) {
-	cam, glctx := ctx.Camera, ctx.GL
-	projection, view, position := cam.Projection(), cam.View(), cam.Position()
+	cam := ctx.Camera
+	camera := cameraUniforms{
+		view:       cam.View(),
+		projection: cam.Projection(),
+		position:   cam.Position(),
+	}
+	maxLights := ctx.MaxLights
+	if maxLights == 0 {
+		maxLights = DefaultMaxLights
+	}
 
-	glctx.UniformMatrix4fv(shader.Uniform("cameraPos"), position[:])
-	glctx.UniformMatrix4fv(shader.Uniform("view"), view[:])
-	glctx.UniformMatrix4fv(shader.Uniform("projection"), projection[:])
+	if ctx.Uniforms != nil {
+		ctx.Uniforms.bind(shader, camera, ctx.Lights, maxLights)
+		return
+	}
+	uploadCamera(shader, camera)
+	bindLights(shader, ctx.Lights, maxLights)
 }
 
 func (ctx *FrameContext) DrawContext(shader loader.Shader) DrawContext {
@@ -32,15 +57,17 @@ func (ctx *FrameContext) DrawContext(shader loader.Shader) DrawContext {
 		GL:     ctx.GL,
 		Camera: ctx.Camera,
 		Shader: shader,
+		Alpha:  ctx.Alpha,
 	}
 	if ctx.activeShader != shader {
 		shader.Use()
 		ctx.activeShader = shader
 	}
 	if ctx.shaderCache == nil {
-		ctx.shaderCache = map[loader.Shader]struct{}{shader: struct{}{}}
-		ctx.bindShader(shader)
-	} else if _, ok := ctx.shaderCache[shader]; !ok {
+		ctx.shaderCache = map[loader.Shader]struct{}{}
+	}
+	if _, ok := ctx.shaderCache[shader]; !ok {
+		ctx.shaderCache[shader] = struct{}{}
 		ctx.bindShader(shader)
 	}
 	return r
@@ -51,15 +78,7 @@ type DrawContext struct {
 	Camera    camera.Camera
 	Shader    loader.Shader
 	Transform *mgl.Mat4
-}
-
-type Light struct {
-	color    mgl.Vec3
-	position mgl.Vec3
-}
-
-func (light *Light) MoveTo(position mgl.Vec3) {
-	light.position = position
+	Alpha     float32 // See FrameContext.Alpha
 }
 
 type Drawable interface {
@@ -68,11 +87,45 @@ type Drawable interface {
 	Shader() loader.Shader
 }
 
-// TODO: node tree with transforms
+// Container is a Drawable which holds child Drawables, such as a Node.
+// Children are drawn after their parent, with the parent's world transform
+// passed through DrawContext.Transform.
+type Container interface {
+	Drawable
+	Add(...Drawable)
+	Remove(Drawable) bool
+	Children() []Drawable
+}
+
+// sceneNode is implemented by Node and anything embedding it, so that parent
+// links can be maintained for Drawables which wrap a Node (like Skybox).
+type sceneNode interface {
+	sceneNode() *Node
+}
+
+// NewNode returns a Node which draws shape using shader. Either may be nil
+// for a Node which only groups its children.
+func NewNode(shape Shape, shader loader.Shader) *Node {
+	return &Node{
+		Shape:  shape,
+		shader: shader,
+	}
+}
+
+// Node is an element of the scene tree. Its transform is local, relative to
+// its parent.
 type Node struct {
 	Shape
 	transform *mgl.Mat4
 	shader    loader.Shader
+
+	parent      *Node
+	children    []Drawable
+	transparent bool
+}
+
+func (node *Node) sceneNode() *Node {
+	return node
 }
 
 func (node *Node) Shader() loader.Shader {
@@ -80,55 +133,217 @@ func (node *Node) Shader() loader.Shader {
 }
 
 func (node *Node) Draw(ctx DrawContext) {
+	if node.Shape == nil {
+		return
+	}
+
 	view := ctx.Camera.View()
 	model := node.Transform(ctx.Transform)
 	normal := model.Mul4(view).Inv().Transpose()
 
 	// Camera space
-	ctx.GL.UniformMatrix4fv(ctx.Shader.Uniform("model"), model[:])
-	ctx.GL.UniformMatrix4fv(ctx.Shader.Uniform("normalMatrix"), normal[:])
+	ctx.Shader.SetMat4("model", model)
+	ctx.Shader.SetMat4("normalMatrix", normal)
 
 	// Bubble to shape
 	node.Shape.Draw(ctx)
 }
 
+// Transform returns the world transform of the node given the world transform
+// of its parent.
 func (node *Node) Transform(parent *mgl.Mat4) mgl.Mat4 {
-	return MultiMul(node.transform, parent)
+	return MultiMul(parent, node.transform)
+}
+
+// SetTransform replaces the local transform of the node. The matrix is kept
+// by reference, so later changes to it are picked up on the next draw.
+func (node *Node) SetTransform(transform *mgl.Mat4) {
+	node.transform = transform
+}
+
+// LocalTransform returns the transform of the node relative to its parent,
+// or nil if it has none.
+func (node *Node) LocalTransform() *mgl.Mat4 {
+	return node.transform
+}
+
+// WorldTransform returns the transform of the node combined with every
+// ancestor.
+func (node *Node) WorldTransform() mgl.Mat4 {
+	if node.parent == nil {
+		return MultiMul(node.transform)
+	}
+	parent := node.parent.WorldTransform()
+	return MultiMul(&parent, node.transform)
+}
+
+// Transparent reports whether the node is drawn in the transparent pass.
+func (node *Node) Transparent() bool {
+	return node.transparent
+}
+
+// SetTransparent moves the node to the transparent pass, drawn after opaque
+// nodes from back to front with alpha blending.
+func (node *Node) SetTransparent(transparent bool) {
+	node.transparent = transparent
+}
+
+// Bounds returns the bounding box of the Shape in the node's local space, if
+// the Shape has one.
+func (node *Node) Bounds() (AABB, bool) {
+	if shape, ok := node.Shape.(interface{ Bounds() AABB }); ok {
+		return shape.Bounds(), true
+	}
+	return AABB{}, false
+}
+
+// Parent returns the node this node is attached to, or nil.
+func (node *Node) Parent() *Node {
+	return node.parent
+}
+
+// Children returns the child Drawables in draw order.
+func (node *Node) Children() []Drawable {
+	return node.children
+}
+
+// Add attaches children to the node. Children which are already attached
+// elsewhere are moved, so Add also serves to reparent.
+func (node *Node) Add(children ...Drawable) {
+	for _, child := range children {
+		if n, ok := child.(sceneNode); ok {
+			childNode := n.sceneNode()
+			for p := node; p != nil; p = p.parent {
+				if p == childNode {
+					panic("gameblocks: cannot add a node to its own subtree")
+				}
+			}
+			childNode.Detach()
+			childNode.parent = node
+		}
+		node.children = append(node.children, child)
+	}
+}
+
+// Remove detaches child from the node, returns false if it was not found.
+func (node *Node) Remove(child Drawable) bool {
+	var childNode *Node
+	if n, ok := child.(sceneNode); ok {
+		childNode = n.sceneNode()
+	}
+	for i, item := range node.children {
+		if item != child {
+			n, ok := item.(sceneNode)
+			if childNode == nil || !ok || n.sceneNode() != childNode {
+				continue
+			}
+		}
+		node.children = append(node.children[:i], node.children[i+1:]...)
+		if childNode != nil {
+			childNode.parent = nil
+		}
+		return true
+	}
+	return false
+}
+
+// Detach removes the node from its parent, if any.
+func (node *Node) Detach() {
+	if node.parent != nil {
+		node.parent.Remove(node)
+	}
+}
+
+// count returns the number of nodes in the subtree, including this one.
+func (node *Node) count() int {
+	n := 1
+	for _, child := range node.children {
+		if c, ok := child.(sceneNode); ok {
+			n += c.sceneNode().count()
+		} else {
+			n++
+		}
+	}
+	return n
 }
 
 func (node *Node) String() string {
-	return fmt.Sprintf("<Shape of %d vertices; transform: %v>", node.Len(), node.transform)
+	if node.Shape == nil {
+		return fmt.Sprintf("<Node of %d children; transform: %v>", len(node.children), node.transform)
+	}
+	return fmt.Sprintf("<Shape of %d vertices; transform: %v>", node.Shape.Len(), node.transform)
 }
 
 type Scene interface {
 	Add(Drawable)
+	Remove(Drawable) bool
+	AddLight(*Light)
+	RemoveLight(*Light) bool
 	Draw(FrameContext)
+	// Stats returns the counts from the last Draw.
+	Stats() RenderStats
+	// Pick returns the nearest Drawable hit by a world space ray, testing
+	// bounding boxes and, if exact, the triangles of StaticShapes.
+	Pick(ray Ray, exact bool) (Hit, bool)
 	String() string
 }
 
 func NewScene() Scene {
-	return &sliceScene{
-		nodes: []Drawable{},
-	}
+	return &treeScene{}
 }
 
-type sliceScene struct {
-	nodes     []Drawable
-	transform *mgl.Mat4
+// treeScene is a Scene backed by a tree of Nodes, rooted at an empty Node.
+type treeScene struct {
+	root   Node
+	lights []*Light
+	queue  RenderQueue
+}
+
+func (scene *treeScene) String() string {
+	return fmt.Sprintf("%d nodes, %d lights", scene.root.count()-1, len(scene.lights))
+}
+
+func (scene *treeScene) Add(item Drawable) {
+	scene.root.Add(item)
 }
 
-func (scene *sliceScene) String() string {
-	return fmt.Sprintf("%d nodes", len(scene.nodes))
+func (scene *treeScene) Remove(item Drawable) bool {
+	return scene.root.Remove(item)
 }
 
-func (scene *sliceScene) Add(item Drawable) {
-	scene.nodes = append(scene.nodes, item)
+func (scene *treeScene) AddLight(light *Light) {
+	scene.lights = append(scene.lights, light)
+}
+
+func (scene *treeScene) RemoveLight(light *Light) bool {
+	for i, l := range scene.lights {
+		if l == light {
+			scene.lights = append(scene.lights[:i], scene.lights[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (scene *treeScene) Stats() RenderStats {
+	return scene.queue.Stats()
+}
+
+func (scene *treeScene) Pick(ray Ray, exact bool) (Hit, bool) {
+	var hit Hit
+	for _, node := range scene.root.children {
+		pick(node, scene.root.transform, ray, exact, &hit)
+	}
+	return hit, hit.Drawable != nil
 }
 
-func (scene *sliceScene) Draw(frame FrameContext) {
-	for _, node := range scene.nodes {
-		ctx := frame.DrawContext(node.Shader())
-		ctx.Transform = scene.transform
-		node.Draw(ctx)
+func (scene *treeScene) Draw(frame FrameContext) {
+	frame.Lights = scene.lights
+	scene.queue.Reset()
+	cam := frame.Camera
+	scene.queue.SetFrustum(NewFrustum(cam.Projection().Mul4(cam.View())))
+	for _, node := range scene.root.children {
+		scene.queue.Push(node, scene.root.transform)
 	}
+	scene.queue.Draw(&frame)
 }
@@ -0,0 +1,171 @@
+package gameblocks
+
+import (
+	"reflect"
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/shazow/go-gameblocks/camera"
+	"github.com/shazow/go-gameblocks/gltest"
+	"github.com/shazow/go-gameblocks/loader"
+)
+
+func TestNodeTree(t *testing.T) {
+	vehicle := NewNode(nil, nil)
+	turret := NewNode(nil, nil)
+	emitter := NewNode(nil, nil)
+
+	vehicle.Add(turret)
+	turret.Add(emitter)
+
+	if emitter.Parent() != turret || turret.Parent() != vehicle {
+		t.Fatal("parent links not set")
+	}
+
+	move := mgl.Translate3D(10, 0, 0)
+	turn := mgl.HomogRotate3DY(mgl.DegToRad(90))
+	offset := mgl.Translate3D(0, 0, 1)
+	vehicle.SetTransform(&move)
+	turret.SetTransform(&turn)
+	emitter.SetTransform(&offset)
+
+	// The emitter is one unit in front of a turret facing +X.
+	got := emitter.WorldTransform().Mul4x1(mgl.Vec4{0, 0, 0, 1}).Vec3()
+	if want := (mgl.Vec3{11, 0, 0}); got.Sub(want).Len() > 1e-4 {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// Reparent the emitter onto the vehicle directly.
+	vehicle.Add(emitter)
+	if len(turret.Children()) != 0 {
+		t.Errorf("emitter still attached to turret: %v", turret.Children())
+	}
+	if emitter.Parent() != vehicle {
+		t.Error("emitter not reparented")
+	}
+	got = emitter.WorldTransform().Mul4x1(mgl.Vec4{0, 0, 0, 1}).Vec3()
+	if want := (mgl.Vec3{10, 0, 1}); got.Sub(want).Len() > 1e-4 {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	if !vehicle.Remove(turret) {
+		t.Error("failed to remove turret")
+	}
+	if vehicle.Remove(turret) {
+		t.Error("removed turret twice")
+	}
+	if turret.Parent() != nil {
+		t.Error("removed turret still has a parent")
+	}
+}
+
+func TestNodeCycle(t *testing.T) {
+	a, b := NewNode(nil, nil), NewNode(nil, nil)
+	a.Add(b)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("adding an ancestor as a child did not panic")
+		}
+	}()
+	b.Add(a)
+}
+
+func TestSceneString(t *testing.T) {
+	scene := NewScene()
+	parent := NewNode(nil, nil)
+	parent.Add(NewNode(nil, nil), NewNode(nil, nil))
+	scene.Add(parent)
+
+	if got, want := scene.String(), "3 nodes, 0 lights"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+const testLightShader = `
+#define MaxLights 2
+struct Light {
+	int type;
+	vec3 color;
+	vec3 position;
+};
+uniform Light lights[MaxLights];
+uniform int numLights;
+`
+
+func TestSceneLights(t *testing.T) {
+	glctx := gltest.NewContext()
+	shader, err := loader.NewShaderSource(glctx, testLightShader, "void main() {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scene := NewScene()
+	scene.Add(NewNode(nil, shader))
+	scene.AddLight(NewDirectionalLight(mgl.Vec3{1, 1, 1}, mgl.Vec3{0, -1, 0}))
+	scene.AddLight(NewPointLight(mgl.Vec3{1, 0, 0}, mgl.Vec3{0, 5, 0}))
+	scene.AddLight(NewPointLight(mgl.Vec3{0, 1, 0}, mgl.Vec3{0, 5, 5}))
+
+	scene.Draw(FrameContext{GL: glctx, Camera: camera.FixedCamera{}, MaxLights: 2})
+
+	numLights := glctx.Filter("Uniform1i")[0]
+	if got := numLights.Args[1]; got != 2 {
+		t.Errorf("got numLights=%v; want 2", got)
+	}
+	pos := shader.Uniform("lights[1].position")
+	for _, call := range glctx.Filter("Uniform3fv") {
+		if call.Args[0] == pos {
+			if got, want := call.Args[1].([]float32), []float32{0, 5, 0}; !reflect.DeepEqual(got, want) {
+				t.Errorf("got lights[1].position=%v; want %v", got, want)
+			}
+			return
+		}
+	}
+	t.Error("lights[1].position was not uploaded")
+}
+
+func TestBindLightsAllocs(t *testing.T) {
+	glctx := gltest.NewContext()
+	shader, err := loader.NewShaderSource(glctx, testLightShader, "void main() {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lights := []*Light{
+		NewDirectionalLight(mgl.Vec3{1, 1, 1}, mgl.Vec3{0, -1, 0}),
+		NewPointLight(mgl.Vec3{1, 0, 0}, mgl.Vec3{0, 5, 0}),
+	}
+	bindLights(shader, lights, DefaultMaxLights)
+	allocs := testing.AllocsPerRun(10, func() {
+		bindLights(shader, lights, DefaultMaxLights)
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations binding unchanged lights; want 0", allocs)
+	}
+}
+
+func TestSceneStrictShader(t *testing.T) {
+	glctx := gltest.NewContext()
+	shader := newTestShader(t, glctx)
+	shader.SetStrict(true)
+	shape, err := NewMeshShape(glctx, Mesh{
+		Positions: []float32{0, 0, 0, 1, 0, 0, 0, 1, 0},
+		Colors:    []float32{1, 0, 0, 1, 0, 1, 0, 1, 0, 0, 1, 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The shader has no vertColor, lights or material, which are optional.
+	scene := NewScene()
+	scene.Add(NewNode(shape, shader))
+	scene.AddLight(NewPointLight(mgl.Vec3{1, 1, 1}, mgl.Vec3{0, 5, 0}))
+	scene.Draw(FrameContext{GL: glctx, Camera: camera.FixedCamera{}})
+	if err := shader.Check(); err != nil {
+		t.Errorf("got %v for optional names the shader omits", err)
+	}
+
+	shader.Uniform("normalMat")
+	if err := shader.Check(); err == nil {
+		t.Error("misspelled lookup not reported")
+	}
+}
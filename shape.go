@@ -1,12 +1,23 @@
 package gameblocks
 
-import "golang.org/x/mobile/gl"
+import (
+	"fmt"
+
+	"github.com/shazow/go-gameblocks/loader"
+	"golang.org/x/mobile/gl"
+)
 
 const vertexDim = 3
 const textureDim = 2
 const normalDim = 3
+const colorDim = 4
+const jointDim = 4 // Skin influences per vertex
 const vecSize = 4
 
+// textureUnit is the unit StaticShape binds its Texture to, exposed to
+// shaders as the texSampler uniform.
+const textureUnit = 0
+
 type Shape interface {
 	Close() error
 	Stride() int
@@ -15,7 +26,7 @@ type Shape interface {
 }
 
 func NewStaticShape(glctx gl.Context) *StaticShape {
-	shape := &StaticShape{glctx: glctx}
+	shape := &StaticShape{glctx: glctx, es3: isES3(glctx)}
 	shape.VBO = glctx.CreateBuffer()
 	shape.IBO = glctx.CreateBuffer()
 	return shape
@@ -30,7 +41,181 @@ type StaticShape struct {
 	vertices []float32 // Vec3
 	textures []float32 // Vec2 (UV)
 	normals  []float32 // Vec3
-	indices  []uint8
+	colors   []float32 // Vec4 (RGBA)
+	joints   []uint16  // jointDim joint indices
+	weights  []float32 // Vec4, one weight per joint
+
+	// Indices are narrowed to indexType by setIndices.
+	indices    DimSlicer
+	numIndices int
+	indexType  gl.Enum
+
+	// layout and bounds of the buffered vertices, set by Buffer.
+	layout VertexLayout
+	bounds AABB
+	enc    Encoder
+
+	// Vertex array objects capturing the layout, per shader since attribute
+	// locations differ between programs. Only used on OpenGL ES 3, as found
+	// once at creation rather than querying the version on every draw.
+	es3  bool
+	vaos map[loader.Shader]shapeVAO
+}
+
+// shapeVAO is a vertex array object and the program whose attribute locations
+// it captured.
+type shapeVAO struct {
+	program gl.Program
+	vao     gl.VertexArray
+}
+
+// Mesh is the vertex data for NewMeshShape. Positions are required; every
+// other attribute is optional, but must have one entry per vertex if given.
+type Mesh struct {
+	Positions []float32 // Vec3
+	TexCoords []float32 // Vec2 (UV)
+	Normals   []float32 // Vec3
+	Colors    []float32 // Vec4 (RGBA)
+
+	// Joints and Weights are the four skin joints influencing each vertex,
+	// indexing the skin's joint matrices, and how much each does. They are
+	// set together.
+	Joints  []uint16
+	Weights []float32
+
+	// Indices of triangle vertices. If empty, every three vertices make a
+	// triangle.
+	Indices []uint32
+}
+
+// OBJMesh returns the vertex data of a group of a loaded .obj model, for
+// NewMeshShape. The group's Material is left to the caller.
+func OBJMesh(group *loader.OBJGroup) Mesh {
+	return Mesh{
+		Positions: group.Positions,
+		TexCoords: group.TexCoords,
+		Normals:   group.Normals,
+		Indices:   group.Indices,
+	}
+}
+
+// Len returns the number of vertices in the mesh.
+func (mesh Mesh) Len() int {
+	return len(mesh.Positions) / vertexDim
+}
+
+// Validate checks that the attribute and index lengths agree.
+func (mesh Mesh) Validate() error {
+	n := mesh.Len()
+	if n == 0 || len(mesh.Positions)%vertexDim != 0 {
+		return fmt.Errorf("mesh: positions must be a non-empty list of Vec3, got %d floats", len(mesh.Positions))
+	}
+	attribs := []struct {
+		name string
+		data []float32
+		dim  int
+	}{
+		{"texture coordinates", mesh.TexCoords, textureDim},
+		{"normals", mesh.Normals, normalDim},
+		{"colors", mesh.Colors, colorDim},
+		{"weights", mesh.Weights, jointDim},
+	}
+	for _, attrib := range attribs {
+		if len(attrib.data) > 0 && len(attrib.data) != n*attrib.dim {
+			return fmt.Errorf("mesh: %d %s for %d vertices, want %d floats", len(attrib.data), attrib.name, n, n*attrib.dim)
+		}
+	}
+	if len(mesh.Joints) != len(mesh.Weights) {
+		return fmt.Errorf("mesh: %d joints for %d weights, want one per weight", len(mesh.Joints), len(mesh.Weights))
+	}
+	if len(mesh.Indices) == 0 {
+		if n%3 != 0 {
+			return fmt.Errorf("mesh: %d vertices is not a whole number of triangles", n)
+		}
+		return nil
+	}
+	if len(mesh.Indices)%3 != 0 {
+		return fmt.Errorf("mesh: %d indices is not a whole number of triangles", len(mesh.Indices))
+	}
+	for _, i := range mesh.Indices {
+		if int(i) >= n {
+			return fmt.Errorf("mesh: index %d out of range for %d vertices", i, n)
+		}
+	}
+	return nil
+}
+
+// NewMeshShape validates the mesh and returns a StaticShape with its data
+// already buffered.
+func NewMeshShape(glctx gl.Context, mesh Mesh) (*StaticShape, error) {
+	if err := mesh.Validate(); err != nil {
+		return nil, err
+	}
+	if len(mesh.Indices) > 0 && indexType(mesh.Len()) == gl.UNSIGNED_INT && !isES3(glctx) && !hasExtension(glctx, "GL_OES_element_index_uint") {
+		return nil, fmt.Errorf("mesh has %d vertices, more than OpenGL ES 2 can index without OES_element_index_uint", mesh.Len())
+	}
+
+	shape := NewStaticShape(glctx)
+	shape.vertices = mesh.Positions
+	shape.textures = mesh.TexCoords
+	shape.normals = mesh.Normals
+	shape.colors = mesh.Colors
+	shape.joints = mesh.Joints
+	shape.weights = mesh.Weights
+	shape.setIndices(mesh.Indices)
+	shape.Buffer()
+	return shape, nil
+}
+
+// indexType returns the smallest index type which can address n vertices.
+// UNSIGNED_INT needs OpenGL ES 3, or the OES_element_index_uint extension on
+// ES 2.
+func indexType(n int) gl.Enum {
+	switch {
+	case n <= 1<<8:
+		return gl.UNSIGNED_BYTE
+	case n <= 1<<16:
+		return gl.UNSIGNED_SHORT
+	}
+	return gl.UNSIGNED_INT
+}
+
+// setIndices stores indices in the smallest type for the current vertices,
+// which must be set first.
+func (shape *StaticShape) setIndices(indices []uint32) {
+	shape.numIndices = len(indices)
+	shape.indexType = indexType(shape.Len())
+	switch {
+	case len(indices) == 0:
+		shape.indices = nil
+	case shape.indexType == gl.UNSIGNED_BYTE:
+		narrow := make([]uint8, len(indices))
+		for i, idx := range indices {
+			narrow[i] = uint8(idx)
+		}
+		shape.indices = NewDimSlice(1, narrow)
+	case shape.indexType == gl.UNSIGNED_SHORT:
+		narrow := make([]uint16, len(indices))
+		for i, idx := range indices {
+			narrow[i] = uint16(idx)
+		}
+		shape.indices = NewDimSlice(1, narrow)
+	default:
+		shape.indices = NewDimSlice(1, indices)
+	}
+}
+
+// index returns the i'th vertex index.
+func (shape *StaticShape) index(i int) int {
+	switch indices := shape.indices.(type) {
+	case *AttribSlice[uint8]:
+		return int(indices.Data()[i])
+	case *AttribSlice[uint16]:
+		return int(indices.Data()[i])
+	case *AttribSlice[uint32]:
+		return int(indices.Data()[i])
+	}
+	panic(fmt.Sprintf("gameblocks: unsupported index type %T", shape.indices))
 }
 
 func (s *StaticShape) Len() int {
@@ -38,14 +223,29 @@ func (s *StaticShape) Len() int {
 }
 
 func (shape *StaticShape) Stride() int {
-	r := vertexDim
+	return shape.Layout().Stride
+}
+
+// Layout returns the interleaved layout of the vertex attributes which are
+// set: vertCoord, vertTexCoord, vertNormal, vertColor, vertJoints and
+// vertWeights, in that order.
+func (shape *StaticShape) Layout() VertexLayout {
+	var layout VertexLayout
+	layout.Add("vertCoord", gl.FLOAT, vertexDim, false)
 	if len(shape.textures) > 0 {
-		r += textureDim
+		layout.Add("vertTexCoord", gl.FLOAT, textureDim, false)
 	}
 	if len(shape.normals) > 0 {
-		r += normalDim
+		layout.Add("vertNormal", gl.FLOAT, normalDim, false)
 	}
-	return r * vecSize
+	if len(shape.colors) > 0 {
+		layout.Add("vertColor", gl.FLOAT, colorDim, false)
+	}
+	if len(shape.joints) > 0 {
+		layout.Add("vertJoints", gl.UNSIGNED_SHORT, jointDim, false)
+		layout.Add("vertWeights", gl.FLOAT, jointDim, false)
+	}
+	return layout
 }
 
 func (shape *StaticShape) Bytes() []byte {
@@ -53,11 +253,14 @@ func (shape *StaticShape) Bytes() []byte {
 }
 
 func (shape *StaticShape) Close() error {
+	shape.deleteVAOs()
 	shape.glctx.DeleteBuffer(shape.VBO)
 	shape.glctx.DeleteBuffer(shape.IBO)
 	return nil
 }
 
+// BytesOffset encodes the interleaved vertices from vertex n on, into a
+// buffer which is reused by the next call.
 func (shape *StaticShape) BytesOffset(n int) []byte {
 	objects := []DimSlicer{NewDimSlice(vertexDim, shape.vertices)}
 	if len(shape.textures) > 0 {
@@ -66,57 +269,124 @@ func (shape *StaticShape) BytesOffset(n int) []byte {
 	if len(shape.normals) > 0 {
 		objects = append(objects, NewDimSlice(normalDim, shape.normals))
 	}
+	if len(shape.colors) > 0 {
+		objects = append(objects, NewDimSlice(colorDim, shape.colors))
+	}
+	if len(shape.joints) > 0 {
+		objects = append(objects, NewDimSlice(jointDim, shape.joints), NewDimSlice(jointDim, shape.weights))
+	}
 
 	length := len(shape.vertices) / vertexDim
-	return EncodeObjects(n, length, objects...)
+	return shape.enc.EncodeLayout(shape.Layout(), n, length, objects...)
 }
 
 func (shape *StaticShape) Draw(ctx DrawContext) {
 	shader := ctx.Shader
 	glctx := ctx.GL
 
-	glctx.BindBuffer(gl.ARRAY_BUFFER, shape.VBO)
-	stride := shape.Stride()
+	vao := shape.bindVAO(glctx, shader)
+	if !vao {
+		glctx.BindBuffer(gl.ARRAY_BUFFER, shape.VBO)
+		shape.layout.Enable(glctx, shader)
+		if shape.numIndices > 0 {
+			glctx.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, shape.IBO)
+		}
+	}
 
-	glctx.EnableVertexAttribArray(shader.Attrib("vertCoord"))
-	glctx.VertexAttribPointer(shader.Attrib("vertCoord"), vertexDim, gl.FLOAT, false, stride, 0)
+	shape.bindTexture(shader)
 
-	if len(shape.normals) > 0 {
-		glctx.EnableVertexAttribArray(shader.Attrib("vertNormal"))
-		glctx.VertexAttribPointer(shader.Attrib("vertNormal"), normalDim, gl.FLOAT, false, stride, vertexDim*vecSize)
+	if shape.numIndices > 0 {
+		glctx.DrawElements(gl.TRIANGLES, shape.numIndices, shape.indexType, 0)
+	} else {
+		glctx.DrawArrays(gl.TRIANGLES, 0, shape.Len())
 	}
-	// TODO: texture
 
-	if len(shape.indices) > 0 {
-		glctx.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, shape.IBO)
-		glctx.DrawElements(gl.TRIANGLES, len(shape.indices), gl.UNSIGNED_BYTE, 0)
+	if vao {
+		glctx.BindVertexArray(gl.VertexArray{})
 	} else {
-		glctx.DrawArrays(gl.TRIANGLES, 0, shape.Len())
+		shape.layout.Disable(glctx, shader)
 	}
+}
 
-	glctx.DisableVertexAttribArray(shader.Attrib("vertCoord"))
-	if len(shape.normals) > 0 {
-		glctx.DisableVertexAttribArray(shader.Attrib("vertNormal"))
+// bindTexture binds the Texture, if any, to texSampler.
+func (shape *StaticShape) bindTexture(shader loader.Shader) {
+	if shape.Texture.Value == 0 {
+		return
 	}
-	// TODO: texture
+	shader.SetTexture("texSampler", textureUnit, gl.TEXTURE_2D, shape.Texture)
+}
+
+// bindVAO binds the vertex array object for drawing with shader, capturing
+// it on first use. Arrays are recaptured when the shader's program changes, as
+// attribute locations do when a shader is reloaded. It returns false on
+// OpenGL ES 2, where the attributes must be set up on every draw instead.
+func (shape *StaticShape) bindVAO(glctx gl.Context, shader loader.Shader) bool {
+	if !shape.es3 {
+		return false
+	}
+	program := shader.Program()
+	if v, ok := shape.vaos[shader]; ok {
+		if v.program == program {
+			glctx.BindVertexArray(v.vao)
+			return true
+		}
+		glctx.DeleteVertexArray(v.vao)
+	}
+
+	vao := glctx.CreateVertexArray()
+	glctx.BindVertexArray(vao)
+	glctx.BindBuffer(gl.ARRAY_BUFFER, shape.VBO)
+	shape.layout.Enable(glctx, shader)
+	if shape.numIndices > 0 {
+		glctx.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, shape.IBO)
+	}
+	if shape.vaos == nil {
+		shape.vaos = map[loader.Shader]shapeVAO{}
+	}
+	shape.vaos[shader] = shapeVAO{program, vao}
+	return true
+}
+
+// deleteVAOs drops the captured vertex arrays, to be recaptured with the
+// current layout on the next draw.
+func (shape *StaticShape) deleteVAOs() {
+	for shader, v := range shape.vaos {
+		shape.glctx.DeleteVertexArray(v.vao)
+		delete(shape.vaos, shader)
+	}
+}
+
+// setLayout replaces the layout, invalidating vertex arrays if it changed.
+func (shape *StaticShape) setLayout(layout VertexLayout) {
+	if !layout.Equal(shape.layout) {
+		shape.deleteVAOs()
+	}
+	shape.layout = layout
+}
+
+// Bounds returns the bounding box of the vertices as of the last Buffer.
+func (shape *StaticShape) Bounds() AABB {
+	return shape.bounds
 }
 
 func (shape *StaticShape) Buffer() {
+	shape.setLayout(shape.Layout())
+	shape.bounds = NewAABB(shape.vertices)
 	data := shape.Bytes()
 	if len(data) > 0 {
 		shape.glctx.BindBuffer(gl.ARRAY_BUFFER, shape.VBO)
 		shape.glctx.BufferData(gl.ARRAY_BUFFER, data, gl.STATIC_DRAW)
 	}
 
-	if len(shape.indices) > 0 {
-		data = EncodeObjects(0, len(shape.indices), NewDimSlice(1, shape.indices))
+	if shape.numIndices > 0 {
+		data = shape.enc.Encode(0, shape.numIndices, shape.indices)
 		shape.glctx.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, shape.IBO)
 		shape.glctx.BufferData(gl.ELEMENT_ARRAY_BUFFER, data, gl.STATIC_DRAW)
 	}
 }
 
 func NewDynamicShape(glctx gl.Context, bufSize int) *DynamicShape {
-	shape := &DynamicShape{StaticShape{glctx: glctx}}
+	shape := &DynamicShape{StaticShape{glctx: glctx, es3: isES3(glctx)}}
 	shape.VBO = glctx.CreateBuffer()
 	glctx.BindBuffer(gl.ARRAY_BUFFER, shape.VBO)
 	glctx.BufferInit(gl.ARRAY_BUFFER, bufSize, gl.DYNAMIC_DRAW)
@@ -129,6 +399,8 @@ type DynamicShape struct {
 }
 
 func (shape *DynamicShape) Buffer(offset int) {
+	shape.setLayout(shape.Layout())
+	shape.bounds = NewAABB(shape.vertices)
 	shape.glctx.BindBuffer(gl.ARRAY_BUFFER, shape.VBO)
 	data := shape.BytesOffset(offset)
 	if len(data) == 0 {
@@ -136,5 +408,3 @@ func (shape *DynamicShape) Buffer(offset int) {
 	}
 	shape.glctx.BufferSubData(gl.ARRAY_BUFFER, offset*shape.Stride(), data)
 }
-
-// TODO: Good render loop: http://www.java-gaming.org/index.php?topic=18710.0
@@ -0,0 +1,336 @@
+package gameblocks
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shazow/go-gameblocks/gltest"
+	"github.com/shazow/go-gameblocks/loader"
+	"golang.org/x/mobile/gl"
+)
+
+const testVertexShader = `
+uniform mat4 model;
+uniform mat4 view;
+uniform mat4 projection;
+uniform mat4 normalMatrix;
+uniform vec3 cameraPos;
+uniform sampler2D texSampler;
+
+attribute vec3 vertCoord;
+attribute vec2 vertTexCoord;
+attribute vec3 vertNormal;
+
+void main() {}
+`
+
+func newTestShader(t *testing.T, glctx gl.Context) loader.Shader {
+	shader, err := loader.NewShaderSource(glctx, testVertexShader, "void main() {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return shader
+}
+
+func TestStaticShapeDraw(t *testing.T) {
+	glctx := gltest.NewContext()
+	shader := newTestShader(t, glctx)
+
+	shape := NewStaticShape(glctx)
+	shape.vertices = []float32{0, 0, 0, 1, 0, 0, 0, 1, 0}
+	shape.normals = []float32{0, 0, 1, 0, 0, 1, 0, 0, 1}
+	shape.setIndices([]uint32{0, 1, 2})
+	shape.Buffer()
+
+	if got, want := len(glctx.BufferBytes(shape.VBO)), 3*shape.Stride(); got != want {
+		t.Errorf("got %d bytes in VBO; want %d", got, want)
+	}
+	if got, want := glctx.BufferBytes(shape.IBO), []byte{0, 1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v in IBO; want %v", got, want)
+	}
+
+	glctx.Reset()
+	shape.Draw(DrawContext{GL: glctx, Shader: shader})
+
+	pointers := glctx.Filter("VertexAttribPointer")
+	if len(pointers) != 2 {
+		t.Fatalf("got %d attribute pointers; want 2", len(pointers))
+	}
+	if got, want := pointers[1].Args[5], vertexDim*vecSize; got != want {
+		t.Errorf("got normal offset %v; want %v", got, want)
+	}
+
+	draws := glctx.Filter("DrawElements", "DrawArrays")
+	if len(draws) != 1 || draws[0].Name != "DrawElements" {
+		t.Fatalf("got draws %v", draws)
+	}
+	if got, want := draws[0].Args[2], gl.Enum(gl.UNSIGNED_BYTE); got != want {
+		t.Errorf("got index type %v; want %v", got, want)
+	}
+}
+
+func TestStaticShapeDrawTextured(t *testing.T) {
+	glctx := gltest.NewContext()
+	shader := newTestShader(t, glctx)
+
+	shape := NewStaticShape(glctx)
+	shape.vertices = []float32{0, 0, 0, 1, 0, 0, 0, 1, 0}
+	shape.textures = []float32{0, 0, 1, 0, 0, 1}
+	shape.normals = []float32{0, 0, 1, 0, 0, 1, 0, 0, 1}
+	shape.Texture = glctx.CreateTexture()
+	shape.Buffer()
+
+	glctx.Reset()
+	shape.Draw(DrawContext{GL: glctx, Shader: shader})
+
+	offsets := map[gl.Attrib]interface{}{}
+	for _, call := range glctx.Filter("VertexAttribPointer") {
+		offsets[call.Args[0].(gl.Attrib)] = call.Args[5]
+	}
+	want := map[gl.Attrib]interface{}{
+		shader.Attrib("vertCoord"):    0,
+		shader.Attrib("vertTexCoord"): vertexDim * vecSize,
+		shader.Attrib("vertNormal"):   (vertexDim + textureDim) * vecSize,
+	}
+	if !reflect.DeepEqual(offsets, want) {
+		t.Errorf("got offsets %v; want %v", offsets, want)
+	}
+
+	binds := glctx.Filter("BindTexture")
+	if len(binds) != 1 || binds[0].Args[1] != shape.Texture {
+		t.Errorf("got %v; want texture %v bound", binds, shape.Texture)
+	}
+	sampler := glctx.Filter("Uniform1i")
+	if len(sampler) != 1 || sampler[0].Args[0] != shader.Uniform("texSampler") || sampler[0].Args[1] != textureUnit {
+		t.Errorf("got %v; want texSampler set to unit %d", sampler, textureUnit)
+	}
+}
+
+func TestMeshValidate(t *testing.T) {
+	triangle := []float32{0, 0, 0, 1, 0, 0, 0, 1, 0}
+	tests := []struct {
+		mesh Mesh
+		ok   bool
+	}{
+		{Mesh{Positions: triangle}, true},
+		{Mesh{Positions: triangle, TexCoords: []float32{0, 0, 1, 0, 0, 1}}, true},
+		{Mesh{Positions: triangle, Colors: []float32{1, 1, 1, 1}}, false},
+		{Mesh{Positions: triangle, Indices: []uint32{0, 1, 2}}, true},
+		{Mesh{Positions: triangle, Indices: []uint32{0, 1, 3}}, false},
+		{Mesh{Positions: triangle, Indices: []uint32{0, 1}}, false},
+		{Mesh{Positions: triangle[:6]}, false},
+		{Mesh{Positions: triangle, Joints: make([]uint16, 12), Weights: make([]float32, 12)}, true},
+		{Mesh{Positions: triangle, Weights: make([]float32, 12)}, false},
+		{Mesh{}, false},
+	}
+	for i, test := range tests {
+		if err := test.mesh.Validate(); (err == nil) != test.ok {
+			t.Errorf("case %d: got error %v; want ok=%v", i, err, test.ok)
+		}
+	}
+}
+
+func TestNewMeshShape(t *testing.T) {
+	glctx := gltest.NewContext()
+	shape, err := NewMeshShape(glctx, Mesh{
+		Positions: []float32{0, 0, 0, 1, 0, 0, 0, 1, 0},
+		Colors:    []float32{1, 0, 0, 1, 0, 1, 0, 1, 0, 0, 1, 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := shape.Stride(), (vertexDim+colorDim)*vecSize; got != want {
+		t.Errorf("got stride %d; want %d", got, want)
+	}
+	if got, want := len(glctx.BufferBytes(shape.VBO)), 3*shape.Stride(); got != want {
+		t.Errorf("got %d bytes buffered; want %d", got, want)
+	}
+}
+
+func TestOBJMesh(t *testing.T) {
+	obj, err := loader.ParseOBJ(strings.NewReader("v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nvn 0 0 1\nf 1//1 2//1 3//1 4//1\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	glctx := gltest.NewContext()
+	shape, err := NewMeshShape(glctx, OBJMesh(obj.Groups[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := shape.Layout().String(); got != "<VertexLayout vertCoord@0 vertNormal@12; stride: 24>" {
+		t.Errorf("got layout %s", got)
+	}
+	if shape.numIndices != 6 {
+		t.Errorf("got %d indices; want 6 for the quad", shape.numIndices)
+	}
+}
+
+func TestNewMeshShapeIndexUint(t *testing.T) {
+	mesh := Mesh{Positions: make([]float32, 3*(1<<16+1)), Indices: []uint32{0, 1, 1 << 16}}
+
+	glctx := gltest.NewContext()
+	if _, err := NewMeshShape(glctx, mesh); err == nil {
+		t.Error("expected error for 32-bit indices on OpenGL ES 2")
+	}
+	glctx.Strings[gl.EXTENSIONS] = "GL_OES_texture_float GL_OES_element_index_uint"
+	if _, err := NewMeshShape(glctx, mesh); err != nil {
+		t.Errorf("got %v with OES_element_index_uint", err)
+	}
+	if _, err := NewMeshShape(gltest.NewContext3(), mesh); err != nil {
+		t.Errorf("got %v on OpenGL ES 3", err)
+	}
+}
+
+func TestNewMeshShapeSkinned(t *testing.T) {
+	glctx := gltest.NewContext()
+	shape, err := NewMeshShape(glctx, Mesh{
+		Positions: []float32{0, 0, 0, 1, 0, 0, 0, 1, 0},
+		Joints:    []uint16{0, 1, 0, 0, 1, 2, 0, 0, 2, 0, 0, 0},
+		Weights:   []float32{0.5, 0.5, 0, 0, 0.5, 0.5, 0, 0, 1, 0, 0, 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout := shape.Layout()
+	if got := layout.String(); got != "<VertexLayout vertCoord@0 vertJoints@12 vertWeights@20; stride: 36>" {
+		t.Errorf("got layout %s", got)
+	}
+	data := glctx.BufferBytes(shape.VBO)
+	if got, want := data[layout.Stride+12:layout.Stride+16], []byte{1, 0, 2, 0}; !bytes.Equal(got, want) {
+		t.Errorf("got second vertex joints %v; want %v", got, want)
+	}
+}
+
+func TestNewMeshShapeIndexType(t *testing.T) {
+	tests := []struct {
+		vertices int
+		want     gl.Enum
+		size     int
+	}{
+		{3, gl.UNSIGNED_BYTE, 1},
+		{255, gl.UNSIGNED_BYTE, 1},
+		{258, gl.UNSIGNED_SHORT, 2},
+		{65538, gl.UNSIGNED_INT, 4},
+	}
+	for _, test := range tests {
+		glctx := gltest.NewContext3() // UNSIGNED_INT indices need ES 3.
+		n := test.vertices
+		mesh := Mesh{Positions: make([]float32, n*vertexDim), Indices: []uint32{0, 1, uint32(n - 1)}}
+		shape, err := NewMeshShape(glctx, mesh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if shape.indexType != test.want {
+			t.Errorf("%d vertices: got index type %v; want %v", n, shape.indexType, test.want)
+		}
+		if got, want := len(glctx.BufferBytes(shape.IBO)), 3*test.size; got != want {
+			t.Errorf("%d vertices: got %d index bytes; want %d", n, got, want)
+		}
+	}
+}
+
+func TestStaticShapeDrawVAO(t *testing.T) {
+	glctx := gltest.NewContext3()
+	shader := newTestShader(t, glctx)
+
+	shape, err := NewMeshShape(glctx, Mesh{
+		Positions: []float32{0, 0, 0, 1, 0, 0, 0, 1, 0},
+		Indices:   []uint32{0, 1, 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	glctx.Reset()
+	shape.Draw(DrawContext{GL: glctx, Shader: shader})
+	if got := len(glctx.Filter("CreateVertexArray")); got != 1 {
+		t.Errorf("got %d vertex arrays created; want 1", got)
+	}
+	if got := len(glctx.Filter("VertexAttribPointer")); got != 1 {
+		t.Errorf("got %d attribute pointers on first draw; want 1", got)
+	}
+
+	glctx.Reset()
+	shape.Draw(DrawContext{GL: glctx, Shader: shader})
+	want := []string{"BindVertexArray", "DrawElements", "BindVertexArray"}
+	if got := glctx.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v on second draw; want %v", got, want)
+	}
+
+	glctx.Reset()
+	shape.Close()
+	if got := len(glctx.Filter("DeleteVertexArray")); got != 1 {
+		t.Errorf("got %d vertex arrays deleted; want 1", got)
+	}
+}
+
+func TestStaticShapeDrawES2Context3(t *testing.T) {
+	// x/mobile/gl hands out a Context3 whenever it is built with ES 3, even
+	// if the device only has ES 2.
+	glctx := gltest.NewContext3()
+	glctx.Strings[gl.VERSION] = "OpenGL ES 2.0"
+	shader := newTestShader(t, glctx)
+	shape, err := NewMeshShape(glctx, Mesh{
+		Positions: []float32{0, 0, 0, 1, 0, 0, 0, 1, 0},
+		Indices:   []uint32{0, 1, 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	glctx.Reset()
+	shape.Draw(DrawContext{GL: glctx, Shader: shader})
+	if got := glctx.Filter("CreateVertexArray", "BindVertexArray"); len(got) != 0 {
+		t.Errorf("got %v on OpenGL ES 2; want no vertex arrays", got)
+	}
+	if got := len(glctx.Filter("VertexAttribPointer")); got != 1 {
+		t.Errorf("got %d attribute pointers; want 1", got)
+	}
+}
+
+// reloadedShader stands in for a loader shader, whose program is replaced
+// when it is reloaded.
+type reloadedShader struct {
+	loader.Shader
+}
+
+func TestStaticShapeVAOReload(t *testing.T) {
+	glctx := gltest.NewContext3()
+	shader := &reloadedShader{newTestShader(t, glctx)}
+	shape, err := NewMeshShape(glctx, Mesh{
+		Positions: []float32{0, 0, 0, 1, 0, 0, 0, 1, 0},
+		Normals:   []float32{0, 0, 1, 0, 0, 1, 0, 0, 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shape.Draw(DrawContext{GL: glctx, Shader: shader})
+
+	// Reordered attributes take each other's locations.
+	reordered, err := loader.NewShaderSource(glctx, `
+attribute vec3 vertNormal;
+attribute vec3 vertCoord;
+void main() {}
+`, "void main() {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shader.Shader = reordered
+	glctx.Reset()
+	shape.Draw(DrawContext{GL: glctx, Shader: shader})
+	if got := len(glctx.Filter("DeleteVertexArray")); got != 1 {
+		t.Errorf("got %d stale vertex arrays deleted; want 1", got)
+	}
+	pointers := glctx.Filter("VertexAttribPointer")
+	if len(pointers) != 2 {
+		t.Fatalf("got %d attribute pointers after reloading; want 2", len(pointers))
+	}
+	if got, want := pointers[0].Args[0], reordered.Attrib("vertCoord"); got != want {
+		t.Errorf("got vertCoord at %v; want %v", got, want)
+	}
+	if got := len(shape.vaos); got != 1 {
+		t.Errorf("got %d vertex arrays kept; want 1", got)
+	}
+}
@@ -30,7 +30,7 @@ var skyboxNormals = []float32{
 	-1.0, 1.0, -1.0,
 }
 
-var skyboxIndices = []uint8{
+var skyboxIndices = []uint32{
 	0, 1, 2, 2, 3, 0,
 	4, 1, 0, 0, 5, 4,
 	2, 6, 7, 7, 3, 2,
@@ -39,11 +39,14 @@ var skyboxIndices = []uint8{
 	1, 4, 2, 2, 4, 6,
 }
 
+// NewSkybox returns a cube around the camera textured by the cube map texture.
+// Its shader must take view and projection as plain uniforms rather than from
+// the Camera block, as the skybox drops the translation from the view.
 func NewSkybox(shader loader.Shader, texture gl.Texture) Drawable {
 	glctx := shader.Context()
 	skyboxShape := NewStaticShape(glctx)
 	skyboxShape.vertices = skyboxVertices
-	skyboxShape.indices = skyboxIndices
+	skyboxShape.setIndices(skyboxIndices)
 	skyboxShape.Buffer()
 	skyboxShape.Texture = texture
 
@@ -69,6 +72,11 @@ func (shape *Skybox) Transform(parent *mgl.Mat4) mgl.Mat4 {
 	return mgl.Ident4()
 }
 
+// Bounds reports none, as the skybox surrounds the camera wherever it is.
+func (shape *Skybox) Bounds() (AABB, bool) {
+	return AABB{}, false
+}
+
 func (node *Skybox) Draw(ctx DrawContext) {
 	cam := ctx.Camera
 	shader := ctx.Shader
@@ -80,19 +88,19 @@ func (node *Skybox) Draw(ctx DrawContext) {
 	glctx.DepthMask(false)
 
 	// FIXME: This overrides the scene renderer, should bypass that work somehow.
+	// Shaders reading the Camera block would ignore these; see NewSkybox.
 	projection, view := cam.Projection(), cam.View().Mat3().Mat4()
-	glctx.UniformMatrix4fv(shader.Uniform("projection"), projection[:])
-	glctx.UniformMatrix4fv(shader.Uniform("view"), view[:])
+	shader.SetMat4("projection", projection)
+	shader.SetMat4("view", view)
 
-	glctx.BindTexture(gl.TEXTURE_CUBE_MAP, shape.Texture)
+	shader.SetTexture("texSampler", textureUnit, gl.TEXTURE_CUBE_MAP, shape.Texture)
 
 	glctx.BindBuffer(gl.ARRAY_BUFFER, shape.VBO)
-	glctx.EnableVertexAttribArray(shader.Attrib("vertCoord"))
-	glctx.VertexAttribPointer(shader.Attrib("vertCoord"), vertexDim, gl.FLOAT, false, shape.Stride(), 0)
+	shape.layout.Enable(glctx, shader)
 
 	glctx.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, shape.IBO)
-	glctx.DrawElements(gl.TRIANGLES, len(shape.indices), gl.UNSIGNED_BYTE, 0)
-	glctx.DisableVertexAttribArray(shader.Attrib("vertCoord"))
+	glctx.DrawElements(gl.TRIANGLES, shape.numIndices, shape.indexType, 0)
+	shape.layout.Disable(glctx, shader)
 
 	glctx.DepthMask(true)
 	glctx.DepthFunc(gl.LESS)
@@ -133,7 +141,7 @@ func (scene *Floor) Draw(ctx DrawContext) {
 	glctx.Clear(gl.STENCIL_BUFFER_BIT)
 
 	// Draw floor
-	glctx.Uniform3fv(shader.Uniform("material.ambient"), []float32{0.2, 0.2, 0.35})
+	shader.SetVec3("material.ambient", mgl.Vec3{0.2, 0.2, 0.35})
 	scene.Shape.Draw(ctx)
 
 	// Draw reflections
@@ -142,13 +150,14 @@ func (scene *Floor) Draw(ctx DrawContext) {
 	glctx.DepthMask(true)
 
 	view := ctx.Camera.View()
-	glctx.Uniform3fv(shader.Uniform("material.ambient"), []float32{0.6, 0.6, 0.6})
+	mirror := scene.Transform(ctx.Transform)
+	shader.SetVec3("material.ambient", mgl.Vec3{0.6, 0.6, 0.6})
 	for _, node := range scene.reflected {
-		model := node.Transform(scene.transform)
-		glctx.UniformMatrix4fv(shader.Uniform("model"), model[:])
+		model := node.Transform(&mirror)
+		shader.SetMat4("model", model)
 
 		normal := model.Mul4(view).Inv().Transpose()
-		glctx.UniformMatrix4fv(shader.Uniform("normalMatrix"), normal[:])
+		shader.SetMat4("normalMatrix", normal)
 
 		node.Draw(ctx)
 	}
@@ -0,0 +1,50 @@
+package gameblocks
+
+import "time"
+
+// FixedStep splits wall-clock time into simulation ticks of a fixed duration,
+// so that the simulation behaves the same at any frame rate.
+// Ref: http://www.java-gaming.org/index.php?topic=18710.0
+type FixedStep struct {
+	// Step is the simulated duration of one tick.
+	Step time.Duration
+	// MaxTicks caps the ticks run per frame, so a slow frame can't cause an
+	// ever growing backlog. Time beyond the cap is dropped.
+	MaxTicks int
+
+	accumulator time.Duration
+}
+
+// NewFixedStep returns a FixedStep running rate ticks per second.
+func NewFixedStep(rate int, maxTicks int) *FixedStep {
+	return &FixedStep{
+		Step:     time.Second / time.Duration(rate),
+		MaxTicks: maxTicks,
+	}
+}
+
+// Advance adds elapsed wall-clock time and returns the number of ticks to
+// simulate for this frame.
+func (s *FixedStep) Advance(elapsed time.Duration) int {
+	s.accumulator += elapsed
+	n := int(s.accumulator / s.Step)
+	if s.MaxTicks > 0 && n > s.MaxTicks {
+		n = s.MaxTicks
+		s.accumulator = 0
+		return n
+	}
+	s.accumulator -= time.Duration(n) * s.Step
+	return n
+}
+
+// Alpha returns how far the current frame is between the last tick and the
+// next, in [0, 1). Drawing can interpolate between the previous and current
+// simulation state by this amount.
+func (s *FixedStep) Alpha() float32 {
+	return float32(s.accumulator) / float32(s.Step)
+}
+
+// Reset drops any accumulated time, such as after a pause.
+func (s *FixedStep) Reset() {
+	s.accumulator = 0
+}
@@ -0,0 +1,33 @@
+package gameblocks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFixedStep(t *testing.T) {
+	s := NewFixedStep(100, 5)
+
+	if n := s.Advance(25 * time.Millisecond); n != 2 {
+		t.Errorf("got %d ticks; want 2", n)
+	}
+	if a := s.Alpha(); a < 0.49 || a > 0.51 {
+		t.Errorf("got alpha %v; want 0.5", a)
+	}
+
+	// The remainder carries over into the next frame.
+	if n := s.Advance(5 * time.Millisecond); n != 1 {
+		t.Errorf("got %d ticks; want 1", n)
+	}
+	if a := s.Alpha(); a != 0 {
+		t.Errorf("got alpha %v; want 0", a)
+	}
+
+	// A long stall is capped and the backlog dropped.
+	if n := s.Advance(time.Second); n != 5 {
+		t.Errorf("got %d ticks; want 5", n)
+	}
+	if n := s.Advance(0); n != 0 {
+		t.Errorf("got %d ticks after stall; want 0", n)
+	}
+}
@@ -0,0 +1,167 @@
+package gameblocks
+
+import (
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/shazow/go-gameblocks/loader"
+	"golang.org/x/mobile/gl"
+)
+
+// UniformBlockContext is a gl.Context with the uniform buffer calls of OpenGL
+// ES 3. golang.org/x/mobile/gl does not expose them, so platforms wrap their
+// context to provide them, as the desktop backend does on OpenGL ES 3. On
+// other contexts, such as the x/mobile backend's, cameras are uploaded to
+// each shader as plain uniforms.
+type UniformBlockContext interface {
+	gl.Context
+	GetUniformBlockIndex(p gl.Program, name string) uint32
+	UniformBlockBinding(p gl.Program, index, binding uint32)
+	BindBufferBase(target gl.Enum, index uint32, b gl.Buffer)
+}
+
+// On a UniformBlockContext, shaders may read the camera from a uniform block
+// shared by all programs instead of from plain uniforms:
+//
+//	layout(std140) uniform Camera {
+//		mat4 view;
+//		mat4 projection;
+//		vec3 cameraPos;
+//	};
+//
+// Skybox shaders are the exception, as described by NewSkybox.
+const (
+	cameraBlockName    = "Camera"
+	cameraBlockBinding = 0
+	cameraBlockSize    = (16 + 16 + 4) * vecSize // std140 pads vec3 to vec4
+)
+
+// cameraUniforms are the camera values uploaded to shaders.
+type cameraUniforms struct {
+	view       mgl.Mat4
+	projection mgl.Mat4
+	position   mgl.Vec3
+}
+
+// shaderUniforms are the lights last uploaded to a shader.
+type shaderUniforms struct {
+	program gl.Program
+	block   bool // Reads the camera from the Camera block
+
+	lights    []Light
+	maxLights int
+	set       bool
+}
+
+// Uniforms remembers the lights uploaded to each shader across frames, so
+// they are only uploaded again when they change, and shares the camera
+// between shaders through a uniform block where possible. Set it on
+// FrameContext.Uniforms and keep it for as long as the shaders.
+//
+// Shaders whose program changed, such as by reloading, are detected and
+// uploaded to again in full.
+type Uniforms struct {
+	glctx   gl.Context
+	shaders map[loader.Shader]*shaderUniforms
+
+	buffer gl.Buffer // Camera block, on a UniformBlockContext
+	camera cameraUniforms
+	loaded bool
+	enc    Encoder
+}
+
+func NewUniforms(glctx gl.Context) *Uniforms {
+	return &Uniforms{
+		glctx:   glctx,
+		shaders: map[loader.Shader]*shaderUniforms{},
+	}
+}
+
+// Reset forgets what was uploaded, so everything is uploaded on next use.
+func (u *Uniforms) Reset() {
+	for shader := range u.shaders {
+		delete(u.shaders, shader)
+	}
+	u.loaded = false
+}
+
+func (u *Uniforms) Close() error {
+	if u.buffer.Value != 0 {
+		u.glctx.DeleteBuffer(u.buffer)
+		u.buffer = gl.Buffer{}
+	}
+	u.Reset()
+	return nil
+}
+
+// bind uploads the camera, and the lights if they differ from the ones the
+// shader last received. Unchanged camera uniforms are skipped by the
+// shader's setters.
+func (u *Uniforms) bind(shader loader.Shader, camera cameraUniforms, lights []*Light, maxLights int) {
+	s, ok := u.shaders[shader]
+	if !ok || s.program != shader.Program() {
+		s = &shaderUniforms{program: shader.Program()}
+		u.shaders[shader] = s
+		if bctx, ok := u.glctx.(UniformBlockContext); ok {
+			index := bctx.GetUniformBlockIndex(s.program, cameraBlockName)
+			if index != gl.INVALID_INDEX {
+				bctx.UniformBlockBinding(s.program, index, cameraBlockBinding)
+				s.block = true
+			}
+		}
+	}
+
+	if s.block {
+		u.loadCamera(camera)
+	} else {
+		uploadCamera(shader, camera)
+	}
+	if !s.set || s.maxLights != maxLights || !lightsEqual(s.lights, lights) {
+		bindLights(shader, lights, maxLights)
+		s.maxLights = maxLights
+		s.lights = s.lights[:0]
+		for _, light := range lights {
+			s.lights = append(s.lights, *light)
+		}
+	}
+	s.set = true
+}
+
+// loadCamera updates the shared Camera block if the camera has changed.
+func (u *Uniforms) loadCamera(camera cameraUniforms) {
+	if u.loaded && u.camera == camera {
+		return
+	}
+	if u.buffer.Value == 0 {
+		u.buffer = u.glctx.CreateBuffer()
+	}
+	u.enc.Reset(cameraBlockSize)
+	u.enc.Float32s(camera.view[:])
+	u.enc.Float32s(camera.projection[:])
+	u.enc.Float32s(camera.position[:])
+	u.enc.Float32s([]float32{0})
+
+	u.glctx.BindBuffer(gl.UNIFORM_BUFFER, u.buffer)
+	u.glctx.BufferData(gl.UNIFORM_BUFFER, u.enc.Bytes(), gl.DYNAMIC_DRAW)
+	u.glctx.(UniformBlockContext).BindBufferBase(gl.UNIFORM_BUFFER, cameraBlockBinding, u.buffer)
+	u.camera = camera
+	u.loaded = true
+}
+
+// uploadCamera sets the camera uniforms of the current program.
+func uploadCamera(shader loader.Shader, camera cameraUniforms) {
+	shader.SetVec3("cameraPos", camera.position)
+	shader.SetMat4("view", camera.view)
+	shader.SetMat4("projection", camera.projection)
+}
+
+// lightsEqual reports whether lights have the values of the uploaded copies.
+func lightsEqual(uploaded []Light, lights []*Light) bool {
+	if len(uploaded) != len(lights) {
+		return false
+	}
+	for i, light := range lights {
+		if uploaded[i] != *light {
+			return false
+		}
+	}
+	return true
+}
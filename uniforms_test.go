@@ -0,0 +1,130 @@
+package gameblocks
+
+import (
+	"testing"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+	"github.com/shazow/go-gameblocks/camera"
+	"github.com/shazow/go-gameblocks/gltest"
+	"github.com/shazow/go-gameblocks/loader"
+	"golang.org/x/mobile/gl"
+)
+
+// countUniform returns the number of calls named name which set loc.
+func countUniform(glctx *gltest.Context, name string, loc gl.Uniform) int {
+	n := 0
+	for _, call := range glctx.Filter(name) {
+		if call.Args[0] == loc {
+			n++
+		}
+	}
+	return n
+}
+
+func TestFrameContextUniforms(t *testing.T) {
+	glctx := gltest.NewContext()
+	var shaders []loader.Shader
+	for i := 0; i < 2; i++ {
+		shader, err := loader.NewShaderSource(glctx, testVertexShader+testLightShader, "void main() {}")
+		if err != nil {
+			t.Fatal(err)
+		}
+		shaders = append(shaders, shader)
+	}
+
+	scene := NewScene()
+	for i := 0; i < 6; i++ {
+		scene.Add(NewNode(nil, shaders[i%2]))
+	}
+	light := NewPointLight(mgl.Vec3{1, 1, 1}, mgl.Vec3{0, 5, 0})
+	scene.AddLight(light)
+
+	cam := camera.NewQuatCamera()
+	uniforms := NewUniforms(glctx)
+	draw := func() {
+		glctx.Reset()
+		scene.Draw(FrameContext{GL: glctx, Camera: cam, Uniforms: uniforms})
+	}
+	// Both shaders have the same sources, so the same locations.
+	count := func(name, uniform string) int {
+		return countUniform(glctx, name, shaders[0].Uniform(uniform))
+	}
+
+	draw()
+	if got := count("Uniform3fv", "cameraPos"); got != 2 {
+		t.Errorf("got %d cameraPos uploads; want one per shader", got)
+	}
+	if got := count("UniformMatrix4fv", "cameraPos"); got != 0 {
+		t.Errorf("cameraPos uploaded as a matrix %d times", got)
+	}
+
+	draw()
+	if got := count("UniformMatrix4fv", "view"); got != 0 {
+		t.Errorf("got %d view uploads for an unchanged camera; want 0", got)
+	}
+	if got := count("Uniform1i", "numLights"); got != 0 {
+		t.Errorf("got %d numLights uploads for unchanged lights; want 0", got)
+	}
+
+	cam.MoveTo(mgl.Vec3{1, 2, 3})
+	light.MoveTo(mgl.Vec3{0, 6, 0})
+	draw()
+	if got := count("UniformMatrix4fv", "view"); got != 2 {
+		t.Errorf("got %d view uploads after moving the camera; want 2", got)
+	}
+	if got := count("Uniform3fv", "lights[0].position"); got != 2 {
+		t.Errorf("got %d light position uploads after moving a light; want 2", got)
+	}
+	if got := count("Uniform1i", "numLights"); got != 0 {
+		t.Errorf("got %d numLights uploads for the same number of lights; want 0", got)
+	}
+}
+
+const testBlockShader = `
+layout(std140) uniform Camera {
+	mat4 view;
+	mat4 projection;
+	vec3 cameraPos;
+};
+uniform mat4 model;
+
+in vec3 vertCoord;
+
+void main() {}
+`
+
+func TestUniformsCameraBlock(t *testing.T) {
+	glctx := gltest.NewContext3()
+	var shaders []loader.Shader
+	for i := 0; i < 2; i++ {
+		shader, err := loader.NewShaderSource(glctx, testBlockShader, "void main() {}")
+		if err != nil {
+			t.Fatal(err)
+		}
+		shaders = append(shaders, shader)
+	}
+
+	scene := NewScene()
+	scene.Add(NewNode(nil, shaders[0]))
+	scene.Add(NewNode(nil, shaders[1]))
+	uniforms := NewUniforms(glctx)
+	cam := camera.NewQuatCamera()
+	scene.Draw(FrameContext{GL: glctx, Camera: cam, Uniforms: uniforms})
+
+	if got := len(glctx.Filter("UniformBlockBinding")); got != 2 {
+		t.Errorf("got %d block bindings; want one per shader", got)
+	}
+	uploads := glctx.Filter("BufferData")
+	if len(uploads) != 1 {
+		t.Fatalf("got %d buffer uploads; want 1 for both shaders", len(uploads))
+	}
+	if got := len(uploads[0].Args[1].([]byte)); got != cameraBlockSize {
+		t.Errorf("got %d bytes in the camera block; want %d", got, cameraBlockSize)
+	}
+	if got := len(glctx.Filter("UniformMatrix4fv", "Uniform3fv")); got != 0 {
+		t.Errorf("got %d plain camera uniform uploads; want 0", got)
+	}
+	if err := uniforms.Close(); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,80 +1,84 @@
 package gameblocks
 
 import (
-	"bytes"
-	"encoding/binary"
 	"fmt"
 	_ "image/png"
+	"strconv"
+	"strings"
 
 	mgl "github.com/go-gl/mathgl/mgl32"
+	"golang.org/x/mobile/gl"
 )
 
-type dimslice_float32 struct {
-	dim   int
-	slice []float32
-}
-
-func (o dimslice_float32) Slice(i, j int) interface{} { return o.slice[i:j] }
-func (o dimslice_float32) Dim() int                   { return o.dim }
-func (o dimslice_float32) String() string {
-	return fmt.Sprintf("<float32 slice: len=%d dim=%d>", len(o.slice), o.dim)
+// NewDimSlice wraps a slice of any AttribType in an AttribSlice. It panics on
+// other types; prefer NewAttribSlice, which is checked at compile time.
+func NewDimSlice(dim int, slice interface{}) DimSlicer {
+	switch slice := slice.(type) {
+	case []int8:
+		return NewAttribSlice(dim, slice)
+	case []uint8:
+		return NewAttribSlice(dim, slice)
+	case []int16:
+		return NewAttribSlice(dim, slice)
+	case []uint16:
+		return NewAttribSlice(dim, slice)
+	case []int32:
+		return NewAttribSlice(dim, slice)
+	case []uint32:
+		return NewAttribSlice(dim, slice)
+	case []float32:
+		return NewAttribSlice(dim, slice)
+	}
+	panic(fmt.Sprintf("invalid slice type: %T", slice))
 }
 
-type dimslice_uint8 struct {
-	dim   int
-	slice []uint8
+// glVersion returns the major version of the context, parsed from its VERSION
+// string: "OpenGL ES 3.0 ..." on ES, or "3.2 ..." on desktop OpenGL. It is 0 if
+// the version can't be parsed.
+func glVersion(glctx gl.Context) int {
+	v := strings.TrimPrefix(glctx.GetString(gl.VERSION), "OpenGL ES ")
+	major, _, _ := strings.Cut(v, ".")
+	n, _ := strconv.Atoi(major)
+	return n
 }
 
-func (o dimslice_uint8) Slice(i, j int) interface{} { return o.slice[i:j] }
-func (o dimslice_uint8) Dim() int                   { return o.dim }
-func (o dimslice_uint8) String() string {
-	return fmt.Sprintf("<uint8 slice: len=%d dim=%d>", len(o.slice), o.dim)
+// isES3 returns whether glctx has the OpenGL ES 3 calls at compile time and
+// the version to back them at runtime, which x/mobile/gl doesn't check.
+func isES3(glctx gl.Context) bool {
+	_, ok := glctx.(gl.Context3)
+	return ok && glVersion(glctx) >= 3
 }
 
-func NewDimSlice(dim int, slice interface{}) DimSlicer {
-	switch slice := slice.(type) {
-	case []float32:
-		return &dimslice_float32{dim, slice}
-	case []uint8:
-		return &dimslice_uint8{dim, slice}
+// hasExtension returns whether glctx lists the named extension.
+func hasExtension(glctx gl.Context, name string) bool {
+	for _, ext := range strings.Fields(glctx.GetString(gl.EXTENSIONS)) {
+		if ext == name {
+			return true
+		}
 	}
-	panic(fmt.Sprintf("invalid slice type: %T", slice))
+	return false
 }
 
 type DimSlicer interface {
 	Slice(int, int) interface{}
 	Dim() int
+	// Size is the encoded size of one element in bytes.
+	Size() int
+	// Encode writes elements i to j to enc.
+	Encode(enc *Encoder, i, j int)
+	// Type is the GL type of the elements, and Normalized whether integers
+	// are mapped to [0, 1] or [-1, 1], as passed to VertexAttribPointer.
+	Type() gl.Enum
+	Normalized() bool
 	String() string
 }
 
-// EncodeObjects converts float32 vertices into a LittleEndian byte array.
-// Offset and length are based on the number of rows per dimension.
-// TODO: Replace with https://github.com/lunixbochs/struc?
+// EncodeObjects converts vertices into a LittleEndian byte array, interleaving
+// one row of each object at a time. Offset and length are based on the number
+// of rows per dimension. Use an Encoder to reuse the buffer between calls.
 func EncodeObjects(offset int, length int, objects ...DimSlicer) []byte {
-	//log.Println("EncodeObjects:", offset, length, objects)
-	// TODO: Pre-allocate? Use a SyncPool?
-	/*
-		dimSum := 0 // yum!
-		for _, obj := range objects {
-			dimSum += obj.Dim()
-		}
-		v := make([]float32, dimSum*length)
-	*/
-
-	buf := bytes.Buffer{}
-
-	for i := offset; i < length; i++ {
-		for _, obj := range objects {
-			data := obj.Slice(i*obj.Dim(), (i+1)*obj.Dim())
-			if err := binary.Write(&buf, binary.LittleEndian, data); err != nil {
-				panic(fmt.Sprintln("binary.Write failed:", err))
-			}
-		}
-	}
-	//fmt.Printf("Wrote %d vertices: %d to %d \t", shape.Len()-n, n, shape.Len())
-	//fmt.Println(wrote)
-
-	return buf.Bytes()
+	var enc Encoder
+	return enc.Encode(offset, length, objects...)
 }
 
 // MultiMul multiplies every non-nil Mat4 reference and returns the result. If